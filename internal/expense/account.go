@@ -0,0 +1,226 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types of payment accounts.
+const (
+	Card = "card"
+	Cash = "cash"
+	Bank = "bank"
+)
+
+// unassigned is the account reported for expenses without an account.
+const unassigned = "unassigned"
+
+// account represents a payment account, such as a card, cash or a bank
+// account, that expenses are paid from.
+type account struct {
+	Name           string `json:"name"`            // Unique name of the account
+	Type           string `json:"type"`            // One of Card, Cash or Bank
+	Currency       string `json:"currency"`        // Currency the account is held in
+	OpeningBalance Money  `json:"opening_balance"` // Balance of the account before any expense
+}
+
+// AccountList represents a list of payment accounts.
+type AccountList []account
+
+// Load reads account data from the specified file and loads it into the AccountList.
+// It returns an error if there is any issue reading or parsing the file.
+func (a *AccountList) Load(filename string) error {
+	return loadJSON(filename, a)
+}
+
+// Save serializes the AccountList to JSON format and writes it to the specified file.
+func (a *AccountList) Save(filename string) error {
+	return saveJSON(filename, a)
+}
+
+// Add adds an account with the given name, type, currency and opening balance.
+// Names are stored in lower case and must be made up of letters, digits, dashes
+// and underscores. An empty currency means DefaultCurrency. It returns an
+// *InputError if a value is invalid or an account with the name already exists.
+func (a *AccountList) Add(name, accountType, currency string, openingBalance Money) error {
+	name = strings.ToLower(name)
+	if !isName(name) {
+		return inputErrorf("invalid account name %q: expected letters, digits, dashes and underscores", name)
+	}
+	if _, err := a.Get(name); err == nil {
+		return inputErrorf("account already exists: %q", name)
+	}
+
+	accountType = strings.ToLower(accountType)
+	if accountType != Card && accountType != Cash && accountType != Bank {
+		return inputErrorf("invalid account type %q: expected card, cash or bank", accountType)
+	}
+
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+
+	*a = append(*a, account{Name: name, Type: accountType, Currency: currency, OpeningBalance: openingBalance})
+	slices.SortFunc(*a, func(x, y account) int { return strings.Compare(x.Name, y.Name) })
+	return nil
+}
+
+// Remove removes the account with the given name. Expenses paid from the
+// account keep its name. It returns a *NotFoundError if no such account exists.
+func (a *AccountList) Remove(name string) error {
+	name = strings.ToLower(name)
+	index := slices.IndexFunc(*a, func(item account) bool { return item.Name == name })
+	if index < 0 {
+		return notFoundf("account not found: no account named %q", name)
+	}
+
+	*a = slices.Delete(*a, index, index+1)
+	return nil
+}
+
+// Get returns the account with the given name, or a *NotFoundError if no such
+// account exists.
+func (a *AccountList) Get(name string) (account, error) {
+	name = strings.ToLower(name)
+	index := slices.IndexFunc(*a, func(item account) bool { return item.Name == name })
+	if index < 0 {
+		return account{}, notFoundf("account not found: no account named %q", name)
+	}
+	return (*a)[index], nil
+}
+
+// List writes the account list to the provided io.Writer in a tabular format.
+func (a *AccountList) List(w io.Writer) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-20s%-10s%-10s%s\n", "Account", "Type", "Currency", "Opening balance"))
+
+	for _, item := range *a {
+		buf.WriteString(fmt.Sprintf("%-20s%-10s%-10s%s\n", item.Name, item.Type, item.Currency, formatAmount(item.OpeningBalance, item.Currency)))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// AccountBalance holds the spending and the balance of an account, as written
+// by Balances and by the JSON output. Amounts are in the currency of the account.
+type AccountBalance struct {
+	Account        string `json:"account"`         // Name of the account
+	Type           string `json:"type"`            // Type of the account
+	Currency       string `json:"currency"`        // Currency of the account
+	OpeningBalance Money  `json:"opening_balance"` // Balance of the account before any expense
+	Income         Money  `json:"income"`          // Total of the income paid into the account
+	Spent          Money  `json:"spent"`           // Total of the expenses paid from the account, less their refunds
+	Balance        Money  `json:"balance"`         // Opening balance plus the income, less the expenses
+}
+
+// Balances returns the income, the spending and the balance of every account.
+// Records in another currency than their account are converted using the exchange rates.
+// It returns an error if an expense cannot be converted.
+func (a *AccountList) Balances(e ExpenseList, rates RateList) ([]AccountBalance, error) {
+	balances := make([]AccountBalance, 0, len(*a))
+	for _, item := range *a {
+		balance := AccountBalance{Account: item.Name, Type: item.Type, Currency: item.Currency, OpeningBalance: item.OpeningBalance}
+		for _, paid := range e.active() {
+			if paid.Account != item.Name {
+				continue
+			}
+
+			amount, err := rates.Convert(paid.Amount, paid.currencyCode(), item.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("expense %d: %w", paid.ID, err)
+			}
+			switch {
+			case paid.income():
+				balance.Income += amount
+			case paid.refund():
+				balance.Spent -= amount
+			default:
+				balance.Spent += amount
+			}
+		}
+
+		balance.Balance = balance.OpeningBalance + balance.Income - balance.Spent
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// Balance writes the opening balance, income, spending and balance of every
+// account to the provided io.Writer in a tabular format. Records in another
+// currency than their account are converted using the exchange rates. It returns an error if
+// an expense cannot be converted.
+func (a *AccountList) Balance(w io.Writer, e ExpenseList, rates RateList) error {
+	balances, err := a.Balances(e, rates)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "Account", "Type", "Opening", "Income", "Spent", "Balance"))
+
+	for _, item := range balances {
+		buf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", item.Account, item.Type, formatAmount(item.OpeningBalance, item.Currency),
+			formatAmount(item.Income, item.Currency), formatAmount(item.Spent, item.Currency), formatAmount(item.Balance, item.Currency)))
+	}
+
+	w.Write(buf.Bytes())
+	return nil
+}
+
+// SetAccount sets the payment account of the expense with the specified ID,
+// and its modification time if the account changed. An empty name clears the
+// account. The account is not checked against an AccountList. It returns a
+// *NotFoundError if no expense outside the trash has the ID.
+func (e *ExpenseList) SetAccount(id int, name string) error {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToLower(name)
+	if (*e)[index].Account == name {
+		return nil
+	}
+	(*e)[index].Account = name
+	(*e)[index].UpdatedAt = time.Now()
+	return nil
+}
+
+// accountName returns the payment account of the expense, or "unassigned" if
+// the expense has no account.
+func (e expense) accountName() string {
+	if e.Account == "" {
+		return unassigned
+	}
+	return e.Account
+}
+
+// TotalsByAccount returns the total of every payment account, sorted
+// alphabetically, and the grand total. Expenses without an account are grouped
+// as "unassigned".
+func (e *ExpenseList) TotalsByAccount() Totals {
+	accounts := make(map[string]Money)
+	totals := e.Totals()
+
+	for _, item := range e.spending() {
+		accounts[item.accountName()] += item.Amount
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(accounts)) {
+		totals.Groups = append(totals.Groups, Group{Name: name, Total: accounts[name]})
+	}
+	return totals
+}
+
+// SummaryByAccount writes the total expenses of every payment account to the
+// provided io.Writer in a tabular format, followed by the grand total. Accounts
+// are sorted alphabetically and expenses without an account are reported as "unassigned".
+func (e *ExpenseList) SummaryByAccount(w io.Writer) {
+	e.TotalsByAccount().WriteTable(w, "Account")
+}
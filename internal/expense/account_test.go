@@ -0,0 +1,117 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestAccountSaveAndLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "accounts.json")
+
+	var accountList expense.AccountList
+	if err := accountList.Add("Visa", expense.Card, "", 1000_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := accountList.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded expense.AccountList
+	if err := loaded.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected %d account, but got %d instead", 1, len(loaded))
+	}
+	if _, err := loaded.Get("visa"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccountAddAndRemove(t *testing.T) {
+	var accountList expense.AccountList
+
+	if err := accountList.Add("visa", expense.Card, "usd", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var input *expense.InputError
+	testCases := []struct {
+		name, accountType, currency string
+	}{
+		{name: "Visa", accountType: expense.Card},
+		{name: "my card", accountType: expense.Card},
+		{name: "wallet", accountType: "crypto"},
+		{name: "wallet", accountType: expense.Cash, currency: "euro"},
+	}
+	for _, tc := range testCases {
+		if err := accountList.Add(tc.name, tc.accountType, tc.currency, 0); !errors.As(err, &input) {
+			t.Errorf("expected an *InputError adding %+v, but got %v instead", tc, err)
+		}
+	}
+
+	if err := accountList.Remove("VISA"); err != nil {
+		t.Fatal(err)
+	}
+	var notFound *expense.NotFoundError
+	if err := accountList.Remove("visa"); !errors.As(err, &notFound) {
+		t.Errorf("expected a *NotFoundError removing a missing account, but got %v instead", err)
+	}
+	if _, err := accountList.Get("visa"); !errors.As(err, &notFound) {
+		t.Errorf("expected a *NotFoundError getting a missing account, but got %v instead", err)
+	}
+}
+
+func TestAccountBalance(t *testing.T) {
+	var accountList expense.AccountList
+	accountList.Add("visa", expense.Card, "USD", 1000_00)
+	accountList.Add("wallet", expense.Cash, "EUR", 50_00)
+
+	var rateList expense.RateList
+	if err := rateList.Set("EUR", "USD", "1.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var expenseList expense.ExpenseList
+	expenseList.Add("Hotel", 100_00, "travel", "", time.Time{})
+	expenseList.Add("Museum", 10_00, "travel", "eur", time.Time{})
+	expenseList.Add("Coffee", 4_00, "food", "eur", time.Time{})
+	expenseList.Add("Snack", 3_00, "food", "", time.Time{})
+	expenseList.AddIncome("Salary", 500_00, "work", "", time.Time{})
+	expenseList.SetAccount(1, "visa")
+	expenseList.SetAccount(2, "Visa")
+	expenseList.SetAccount(3, "wallet")
+	expenseList.SetAccount(5, "visa")
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "Account", "Type", "Opening", "Income", "Spent", "Balance"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "visa", "card", "$1000.00", "$500.00", "$111.00", "$1389.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "wallet", "cash", "€50.00", "€0.00", "€4.00", "€46.00"))
+
+	var gotBuf bytes.Buffer
+	if err := accountList.Balance(&gotBuf, expenseList, rateList); err != nil {
+		t.Fatal(err)
+	}
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	expectedBuf.Reset()
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Account", "Total"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "unassigned", "$3.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "visa", "$100.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$103.00"))
+
+	gotBuf.Reset()
+	usdList, _ := expenseList.Where("currency = usd")
+	usdList.SummaryByAccount(&gotBuf)
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
@@ -0,0 +1,162 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// overall is the name used to display the budget that applies to all categories.
+const overall = "overall"
+
+// budget represents a monthly spending limit in DefaultCurrency. A budget without a
+// category applies to all expenses, otherwise it only applies to the expenses in its category.
+type budget struct {
+	Category string `json:"category,omitempty"` // Category the budget applies to, empty for all expenses
+	Amount   Money  `json:"amount"`             // Monthly limit of the budget
+}
+
+// name returns the category of the budget, or "overall" if the budget applies
+// to all expenses.
+func (b budget) name() string {
+	if b.Category == "" {
+		return overall
+	}
+	return b.Category
+}
+
+// BudgetList represents a list of monthly budgets.
+type BudgetList []budget
+
+// Load reads budget data from the specified file and loads it into the BudgetList.
+// It returns an error if there is any issue reading or parsing the file.
+func (b *BudgetList) Load(filename string) error {
+	return loadJSON(filename, b)
+}
+
+// Save serializes the BudgetList to JSON format and writes it to the specified file.
+func (b *BudgetList) Save(filename string) error {
+	return saveJSON(filename, b)
+}
+
+// Set sets the monthly budget for the given category. An empty category sets
+// the overall budget. An existing budget for the same category is replaced.
+// It returns an error if the amount is not positive.
+func (b *BudgetList) Set(category string, amount Money) error {
+	if amount <= 0 {
+		return inputErrorf("invalid amount: budget must be positive")
+	}
+
+	category = strings.ToLower(category)
+	if index := slices.IndexFunc(*b, func(item budget) bool { return item.Category == category }); index >= 0 {
+		(*b)[index].Amount = amount
+		return nil
+	}
+
+	*b = append(*b, budget{Category: category, Amount: amount})
+	slices.SortFunc(*b, func(x, y budget) int { return strings.Compare(x.Category, y.Category) })
+	return nil
+}
+
+// Remove removes the monthly budget for the given category. An empty category
+// removes the overall budget. It returns a *NotFoundError if no such budget exists.
+func (b *BudgetList) Remove(category string) error {
+	category = strings.ToLower(category)
+	index := slices.IndexFunc(*b, func(item budget) bool { return item.Category == category })
+	if index < 0 {
+		return notFoundf("budget not found: no budget for %s", budget{Category: category}.name())
+	}
+
+	*b = slices.Delete(*b, index, index+1)
+	return nil
+}
+
+// List writes the budget list to the provided io.Writer in a tabular format.
+func (b *BudgetList) List(w io.Writer) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-20s%s\n", "Category", "Budget"))
+
+	for _, item := range *b {
+		buf.WriteString(fmt.Sprintf("%-20s%s\n", item.name(), formatAmount(item.Amount, DefaultCurrency)))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// Warnings checks the overall budget and the budgets of the given categories, such
+// as the categories of the parts of a split expense, against the expenses incurred in
+// the month of the given date, and returns a warning message for every budget that
+// is exceeded. The expenses must already be converted into DefaultCurrency.
+func (b *BudgetList) Warnings(e ExpenseList, date time.Time, categories ...string) []string {
+	var warnings []string
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, expense{Category: strings.ToLower(category)}.categoryName())
+	}
+
+	for _, item := range *b {
+		if item.Category != "" && !slices.Contains(names, item.Category) {
+			continue
+		}
+
+		spent := e.spent(date.Year(), date.Month(), item.Category)
+		if spent > item.Amount {
+			warnings = append(warnings, fmt.Sprintf("%s budget for %s %d exceeded: spent %s of %s",
+				item.name(), date.Month(), date.Year(), formatAmount(spent, DefaultCurrency), formatAmount(item.Amount, DefaultCurrency)))
+		}
+	}
+
+	return warnings
+}
+
+// BudgetStatus holds the amount spent against a budget in a month, as written
+// by Report and by the JSON output.
+type BudgetStatus struct {
+	Budget    string `json:"budget"`    // Category of the budget, or "overall"
+	Spent     Money  `json:"spent"`     // Amount spent in the month
+	Limit     Money  `json:"limit"`     // Monthly limit of the budget
+	Remaining Money  `json:"remaining"` // Amount left to spend, negative if the budget is exceeded
+}
+
+// Statuses returns the spent, budget and remaining amounts of every budget for
+// the given month of the given year. The expenses must already be converted into
+// DefaultCurrency. The month parameter should be an integer between 1 and 12.
+// If the month is out of range, an error is returned.
+func (b *BudgetList) Statuses(e ExpenseList, year, month int) ([]BudgetStatus, error) {
+	if month < 1 || month > 12 {
+		return nil, inputErrorf("invalid month: month is out of range")
+	}
+
+	statuses := make([]BudgetStatus, 0, len(*b))
+	for _, item := range *b {
+		spent := e.spent(year, time.Month(month), item.Category)
+		statuses = append(statuses, BudgetStatus{Budget: item.name(), Spent: spent, Limit: item.Amount, Remaining: item.Amount - spent})
+	}
+	return statuses, nil
+}
+
+// Report writes the spent, budget and remaining amounts of every budget for the
+// given month of the given year to the provided io.Writer in a tabular format.
+// The expenses must already be converted into DefaultCurrency.
+// The month parameter should be an integer between 1 and 12.
+// If the month is out of range, an error is returned.
+func (b *BudgetList) Report(w io.Writer, e ExpenseList, year, month int) error {
+	statuses, err := b.Statuses(e, year, month)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-20s%-14s%-14s%s\n", "Budget", "Spent", "Limit", "Remaining"))
+
+	for _, item := range statuses {
+		buf.WriteString(fmt.Sprintf("%-20s%-14s%-14s%s\n", item.Budget, formatAmount(item.Spent, DefaultCurrency),
+			formatAmount(item.Limit, DefaultCurrency), formatAmount(item.Remaining, DefaultCurrency)))
+	}
+
+	w.Write(buf.Bytes())
+	return nil
+}
@@ -0,0 +1,213 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestBudgetSaveAndLoad(t *testing.T) {
+	// Create a temp file for holding budget list.
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var budgetList expense.BudgetList
+
+	// Set an overall and a category budget.
+	if err := budgetList.Set("", 500_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("Food", 100_00); err != nil {
+		t.Fatal(err)
+	}
+
+	// Save the budget list into the tempFile.
+	if err := budgetList.Save(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var newBudgetList expense.BudgetList
+
+	// Read new budget list from the tempFile.
+	if err := newBudgetList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf, gotBuf bytes.Buffer
+	budgetList.List(&expectedBuf)
+	newBudgetList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestBudgetSetAndRemove(t *testing.T) {
+	var budgetList expense.BudgetList
+
+	// Set some budgets, replacing the food budget.
+	if err := budgetList.Set("food", 100_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("", 500_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("Food", 150_00); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert that a budget must be positive.
+	if err := budgetList.Set("travel", 0); err == nil {
+		t.Error("expected an error for a zero budget, but got nil instead")
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Category", "Budget"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "overall", 500.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "food", 150.0))
+
+	var gotBuf bytes.Buffer
+	budgetList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	// Remove the overall budget.
+	if err := budgetList.Remove(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(budgetList) != 1 {
+		t.Errorf("expected length of the budget list: %d, but got %d instead", 1, len(budgetList))
+	}
+
+	// Assert that removing a missing budget fails.
+	if err := budgetList.Remove("travel"); err == nil {
+		t.Error("expected an error for a missing budget, but got nil instead")
+	}
+}
+
+func TestBudgetWarnings(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var budgetList expense.BudgetList
+
+	if err := budgetList.Set("", 300_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("food", 100_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("travel", 50_00); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	// Stay within every budget.
+	if err := expenseList.Add("Demo Expense 1", 100_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings := budgetList.Warnings(expenseList, now, "food"); len(warnings) != 0 {
+		t.Errorf("expected no warnings, but got %q instead", warnings)
+	}
+
+	// Exceed the food and the overall budget.
+	if err := expenseList.Add("Demo Expense 2", 250_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		fmt.Sprintf("overall budget for %s %d exceeded: spent $%.2f of $%.2f", now.Month(), now.Year(), 350.0, 300.0),
+		fmt.Sprintf("food budget for %s %d exceeded: spent $%.2f of $%.2f", now.Month(), now.Year(), 350.0, 100.0),
+	}
+
+	warnings := budgetList.Warnings(expenseList, now, "food")
+	if fmt.Sprint(warnings) != fmt.Sprint(expected) {
+		t.Errorf("expected %q, but got %q instead", expected, warnings)
+	}
+
+	// Split the second expense so that a part of it exceeds the travel budget.
+	if err := expenseList.Split(2, []expense.Part{{Category: "food", Amount: 150_00}, {Category: "travel", Amount: 100_00}}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{
+		fmt.Sprintf("overall budget for %s %d exceeded: spent $%.2f of $%.2f", now.Month(), now.Year(), 350.0, 300.0),
+		fmt.Sprintf("food budget for %s %d exceeded: spent $%.2f of $%.2f", now.Month(), now.Year(), 250.0, 100.0),
+		fmt.Sprintf("travel budget for %s %d exceeded: spent $%.2f of $%.2f", now.Month(), now.Year(), 100.0, 50.0),
+	}
+
+	warnings = budgetList.Warnings(expenseList, now, "food", "travel")
+	if fmt.Sprint(warnings) != fmt.Sprint(expected) {
+		t.Errorf("expected %q, but got %q instead", expected, warnings)
+	}
+}
+
+func TestBudgetReport(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var budgetList expense.BudgetList
+
+	if err := budgetList.Set("", 300_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := budgetList.Set("food", 100_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 1", 40_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 2", 60_00, "travel", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-14s%-14s%s\n", "Budget", "Spent", "Limit", "Remaining"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-14s%-14s$%.2f\n", "overall", "$100.00", "$300.00", 200.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%-14s%-14s$%.2f\n", "food", "$40.00", "$100.00", 60.0))
+
+	now := time.Now()
+	var gotBuf bytes.Buffer
+	if err := budgetList.Report(&gotBuf, expenseList, now.Year(), int(now.Month())); err != nil {
+		t.Fatal(err)
+	}
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestBudgetStatuses(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var budgetList expense.BudgetList
+
+	if err := budgetList.Set("food", 100_00); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 1", 140_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	statuses, err := budgetList.Statuses(expenseList, now.Year(), int(now.Month()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []expense.BudgetStatus{{Budget: "food", Spent: 140_00, Limit: 100_00, Remaining: -40_00}}
+	if fmt.Sprint(statuses) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, but got %v instead", expected, statuses)
+	}
+
+	var input *expense.InputError
+	if _, err := budgetList.Statuses(expenseList, now.Year(), 13); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for an invalid month, but got %v instead", err)
+	}
+}
@@ -0,0 +1,411 @@
+package expense
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader is the header row written by ExportCSV.
+var csvHeader = []string{"id", "date", "description", "category", "amount", "currency", "recurring_id", "created_at", "updated_at", "kind", "tags", "account", "refund_of", "parts"}
+
+// ExportCSV writes every expense in the ExpenseList that is not in the trash to
+// the provided io.Writer as CSV, preceded by a header row. Dates are written in
+// RFC 3339 format, tags are separated by commas and the parts of a split
+// expense are written as category=amount separated by semicolons.
+func (e *ExpenseList) ExportCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, item := range e.active() {
+		recurringID := ""
+		if item.RecurringID != 0 {
+			recurringID = strconv.Itoa(item.RecurringID)
+		}
+		refundOf := ""
+		if item.RefundOf != 0 {
+			refundOf = strconv.Itoa(item.RefundOf)
+		}
+
+		record := []string{
+			strconv.Itoa(item.ID),
+			item.Date.Format(time.RFC3339),
+			item.Description,
+			item.Category,
+			item.Amount.String(),
+			item.currencyCode(),
+			recurringID,
+			formatTimestamp(item.CreatedAt),
+			formatTimestamp(item.UpdatedAt),
+			item.kindName(),
+			strings.Join(item.Tags, ","),
+			item.Account,
+			refundOf,
+			formatCSVParts(item),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// CSVMapping describes which columns of a CSV file hold the fields of an expense.
+// A column is identified either by its name in the header row, compared
+// case-insensitively, or by its 1-based position. The zero value matches the
+// files written by ExportCSV.
+type CSVMapping struct {
+	Date        string // Column of the date, "date" if empty
+	Description string // Column of the description, "description" if empty
+	Amount      string // Column of the amount, "amount" if empty
+	Category    string // Optional column of the category, "category" if present and empty
+	Currency    string // Optional column of the currency, "currency" if present and empty
+	Kind        string // Optional column of the kind, expense or income, "kind" if present and empty
+	Tags        string // Optional column of the tags separated by commas, "tags" if present and empty
+	Account     string // Optional column of the payment account, "account" if present and empty
+
+	Accounts AccountList // Accounts of the ledger, the only ones the payment accounts may name
+
+	DateLayout      string // Layout of the dates as understood by time.Parse, RFC 3339 or YYYY-MM-DD if empty
+	DefaultCurrency string // Currency of the rows without a currency column, DefaultCurrency if empty
+	Negate          bool   // Whether to flip the sign of the amounts, for statements that list spending as negative
+	Comma           rune   // Field delimiter, ',' if zero
+	Decimal         rune   // Decimal separator of the amounts, '.' or ',', '.' if zero
+}
+
+// RejectedRow describes a CSV row that was not imported.
+type RejectedRow struct {
+	Line   int    // Line of the row in the CSV file
+	Reason string // Why the row was rejected
+}
+
+// String returns the string representation of the RejectedRow.
+func (r RejectedRow) String() string {
+	return fmt.Sprintf("line %d: %s", r.Line, r.Reason)
+}
+
+// ImportCSV adds an expense to the ExpenseList for every row of the CSV read
+// from r, using the mapping to find the fields of each expense. Rows whose kind
+// column is "income" are added as income, the tags column, if any, tags the
+// expenses, the account column, if any, records the account they were paid from,
+// which must be one of the Accounts of the mapping, and the parts column, if
+// any, splits them. Rows whose kind is
+// "refund" are added as refunds of the expense whose id column matches their
+// refund_of column, as written by ExportCSV, if that expense was imported too.
+// The first row must be a header row. Rows that cannot be imported are skipped
+// and returned along with the reason they were rejected. It returns an error if
+// the CSV cannot be read or a required column is missing.
+func (e *ExpenseList) ImportCSV(r io.Reader, mapping CSVMapping) ([]RejectedRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if mapping.Comma != 0 {
+		reader.Comma = mapping.Comma
+	}
+
+	decimal := mapping.Decimal
+	if decimal == 0 {
+		decimal = '.'
+	}
+	if decimal != '.' && decimal != ',' {
+		return nil, inputErrorf("invalid decimal separator %q: expected '.' or ','", decimal)
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("invalid csv: missing header row")
+		}
+		return nil, err
+	}
+
+	dateColumn, err := columnIndex(header, mapping.Date, "date", true)
+	if err != nil {
+		return nil, err
+	}
+	descriptionColumn, err := columnIndex(header, mapping.Description, "description", true)
+	if err != nil {
+		return nil, err
+	}
+	amountColumn, err := columnIndex(header, mapping.Amount, "amount", true)
+	if err != nil {
+		return nil, err
+	}
+	categoryColumn, err := columnIndex(header, mapping.Category, "category", mapping.Category != "")
+	if err != nil {
+		return nil, err
+	}
+	currencyColumn, err := columnIndex(header, mapping.Currency, "currency", mapping.Currency != "")
+	if err != nil {
+		return nil, err
+	}
+	kindColumn, err := columnIndex(header, mapping.Kind, "kind", mapping.Kind != "")
+	if err != nil {
+		return nil, err
+	}
+	tagsColumn, err := columnIndex(header, mapping.Tags, "tags", mapping.Tags != "")
+	if err != nil {
+		return nil, err
+	}
+	accountColumn, err := columnIndex(header, mapping.Account, "account", mapping.Account != "")
+	if err != nil {
+		return nil, err
+	}
+	idColumn, _ := columnIndex(header, "", "id", false)
+	refundOfColumn, _ := columnIndex(header, "", "refund_of", false)
+	partsColumn, _ := columnIndex(header, "", "parts", false)
+
+	// The IDs the expenses had in the file, as written by ExportCSV, and the
+	// IDs they were imported with, to link refunds to their expense.
+	imported := make(map[string]int)
+
+	var rejected []RejectedRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rejected = append(rejected, RejectedRow{Line: parseErr.Line, Reason: parseErr.Err.Error()})
+				continue
+			}
+			return rejected, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(column int) string {
+			if column < 0 || column >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[column])
+		}
+
+		if dateColumn >= len(record) || descriptionColumn >= len(record) || amountColumn >= len(record) {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: "missing columns"})
+			continue
+		}
+
+		date, err := parseCSVDate(field(dateColumn), mapping.DateLayout)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			continue
+		}
+
+		amount, err := parseCSVAmount(field(amountColumn), decimal)
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			continue
+		}
+		if mapping.Negate {
+			amount = -amount
+		}
+
+		currency := field(currencyColumn)
+		if currency == "" {
+			currency = mapping.DefaultCurrency
+		}
+
+		add := e.Add
+		switch kind := strings.ToLower(field(kindColumn)); kind {
+		case "", KindExpense:
+		case KindIncome:
+			add = e.AddIncome
+		case KindRefund:
+			original, ok := imported[field(refundOfColumn)]
+			if !ok {
+				rejected = append(rejected, RejectedRow{Line: line, Reason: "refunds are only imported along with their expense"})
+				continue
+			}
+			if err := e.Refund(original, amount, field(descriptionColumn)); err != nil {
+				rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			}
+			continue
+		default:
+			rejected = append(rejected, RejectedRow{Line: line, Reason: fmt.Sprintf("invalid kind %q: expected expense or income", kind)})
+			continue
+		}
+
+		tags, err := parseCSVTags(field(tagsColumn))
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			continue
+		}
+		parts, err := parseCSVParts(field(partsColumn))
+		if err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			continue
+		}
+		account := strings.ToLower(field(accountColumn))
+		if account != "" {
+			if _, err := mapping.Accounts.Get(account); err != nil {
+				rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+				continue
+			}
+		}
+
+		if err := add(field(descriptionColumn), amount, field(categoryColumn), currency, date); err != nil {
+			rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+			continue
+		}
+		(*e)[len(*e)-1].Tags = tags
+		(*e)[len(*e)-1].Account = account
+		if len(parts) > 0 {
+			if err := e.Split((*e)[len(*e)-1].ID, parts); err != nil {
+				*e = (*e)[:len(*e)-1]
+				rejected = append(rejected, RejectedRow{Line: line, Reason: err.Error()})
+				continue
+			}
+		}
+		if id := field(idColumn); id != "" {
+			imported[id] = (*e)[len(*e)-1].ID
+		}
+	}
+
+	return rejected, nil
+}
+
+// columnIndex returns the 0-based index of the column identified by name, or
+// by fallback if name is empty. It returns -1 if an optional column is missing
+// and an error if a required column is missing.
+func columnIndex(header []string, name, fallback string, required bool) (int, error) {
+	if name == "" {
+		name = fallback
+	}
+
+	if position, err := strconv.Atoi(name); err == nil {
+		if position < 1 || position > len(header) {
+			return -1, fmt.Errorf("invalid column %d: the csv has %d columns", position, len(header))
+		}
+		return position - 1, nil
+	}
+
+	for index, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return index, nil
+		}
+	}
+
+	if required {
+		return -1, fmt.Errorf("invalid csv: missing %s column %q", fallback, name)
+	}
+	return -1, nil
+}
+
+// parseCSVDate parses a date from a CSV row using the layout, or as an RFC 3339
+// timestamp or YYYY-MM-DD date if the layout is empty.
+func parseCSVDate(value, layout string) (time.Time, error) {
+	if layout != "" {
+		date, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q: expected %s", value, layout)
+		}
+		return date, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// parseCSVAmount parses an amount written with the given decimal separator, '.'
+// or ',', and the other one as an optional thousands separator, such as
+// "-1,200.50" or "-1.200,50". It returns an *InputError if a thousands separator
+// is not followed by groups of three digits, as in "12,50" with a decimal point,
+// rather than guessing which of the separators was meant.
+func parseCSVAmount(value string, decimal rune) (Money, error) {
+	thousands := ","
+	if decimal == ',' {
+		thousands = "."
+	}
+
+	whole, fraction, found := strings.Cut(value, string(decimal))
+	if strings.Contains(whole, thousands) {
+		groups := strings.Split(strings.TrimLeft(whole, "+-"), thousands)
+		for index, group := range groups {
+			if group == "" || len(group) > 3 || index > 0 && len(group) != 3 {
+				return 0, inputErrorf("invalid amount %q: ambiguous %q, check the decimal separator", value, thousands)
+			}
+		}
+		whole = strings.ReplaceAll(whole, thousands, "")
+	}
+
+	if found {
+		whole += "." + fraction
+	}
+	amount, err := ParseMoney(whole)
+	if err != nil && whole != value {
+		return 0, inputErrorf("invalid amount %q", value)
+	}
+	return amount, err
+}
+
+// parseCSVTags parses tags separated by commas, as written by ExportCSV. It
+// returns nil if there are no tags, or an *InputError if a tag is invalid.
+func parseCSVTags(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	tags, err := normalizeTags(strings.Split(value, ","))
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// formatCSVParts returns the parts of a split expense as category=amount
+// separated by semicolons, or an empty string if the expense is not split.
+// The parts of a refund are left out, as they follow its expense.
+func formatCSVParts(item expense) string {
+	if item.refund() {
+		return ""
+	}
+
+	formatted := make([]string, 0, len(item.Parts))
+	for _, part := range item.Parts {
+		formatted = append(formatted, part.String())
+	}
+	return strings.Join(formatted, ";")
+}
+
+// parseCSVParts parses parts written as category=amount separated by
+// semicolons, as written by ExportCSV. It returns nil if there are no parts,
+// or an *InputError if a part is invalid.
+func parseCSVParts(value string) ([]Part, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var parts []Part
+	for _, field := range strings.Split(value, ";") {
+		part, err := ParsePart(field)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// formatTimestamp formats the time in RFC 3339 format, or as an empty string if it is zero.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,230 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestExportAndImportCSV(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	// Add some expenses.
+	if err := expenseList.Add("Demo Expense 1", 100_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo, \"Expense\" 2", 20_60, "", "EUR", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Salary", 2500_00, "work", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.SetTags(2, []string{"trip", "paris"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.SetAccount(1, "visa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Refund(1, 40_00, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Split(1, []expense.Part{{Category: "food", Amount: 70_00}, {Category: "household", Amount: 30_00}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := expenseList.ExportCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Now().Format(time.RFC3339)
+	expected := "id,date,description,category,amount,currency,recurring_id,created_at,updated_at,kind,tags,account,refund_of,parts\n" +
+		fmt.Sprintf("1,%s,demo expense 1,food,100.00,USD,,%s,%s,expense,,visa,,food=70.00;household=30.00\n", date, date, date) +
+		fmt.Sprintf("2,%s,\"demo, \"\"expense\"\" 2\",,20.60,EUR,,%s,%s,expense,\"paris,trip\",,,\n", date, date, date) +
+		fmt.Sprintf("3,%s,salary,work,2500.00,USD,,%s,%s,income,,,,\n", date, date, date) +
+		fmt.Sprintf("4,%s,refund: demo expense 1,food,40.00,USD,,%s,%s,refund,,visa,1,\n", date, date, date)
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+
+	// Import the exported expenses into a new list of a ledger with the same account.
+	var accountList expense.AccountList
+	if err := accountList.Add("visa", expense.Card, "", 0); err != nil {
+		t.Fatal(err)
+	}
+	var newExpenseList expense.ExpenseList
+	rejected, err := newExpenseList.ImportCSV(&buf, expense.CSVMapping{Accounts: accountList})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 0 {
+		t.Errorf("expected no rejected rows, but got %v instead", rejected)
+	}
+
+	var expectedBuf, gotBuf bytes.Buffer
+	expenseList.List(&expectedBuf)
+	newExpenseList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	if item, _ := newExpenseList.Get(2); fmt.Sprint(item.Tags) != "[paris trip]" {
+		t.Errorf("expected the tags [paris trip], but got %v instead", item.Tags)
+	}
+	if item, _ := newExpenseList.Get(1); item.Account != "visa" {
+		t.Errorf("expected the account %q, but got %q instead", "visa", item.Account)
+	}
+	if expected, got := expenseList.TotalsByAccount(), newExpenseList.TotalsByAccount(); fmt.Sprint(expected) != fmt.Sprint(got) {
+		t.Errorf("expected the totals by account %v, but got %v instead", expected, got)
+	}
+
+	// Assert rows paid from an account the ledger does not define are rejected.
+	if err := expenseList.ExportCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var otherExpenseList expense.ExpenseList
+	if rejected, err = otherExpenseList.ImportCSV(&buf, expense.CSVMapping{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 2 || rejected[0].Line != 2 {
+		t.Errorf("expected the expense and its refund to be rejected, but got %v instead", rejected)
+	}
+	if expected, got := expenseList.TotalsByCategory(), newExpenseList.TotalsByCategory(); fmt.Sprint(expected) != fmt.Sprint(got) {
+		t.Errorf("expected the totals by category %v, but got %v instead", expected, got)
+	}
+}
+
+func TestImportBankCSV(t *testing.T) {
+	statement := `Posted;Details;Debit;Balance
+03/08/2025;STARBUCKS COFFEE;-4.50;995.50
+04/08/2025;RENT;-1,200.00;-204.50
+2025-08-05;UBER;-12.00;-216.50
+06/08/2025;;-3.00;-219.50
+07/08/2025;REFUND;8.00;-211.50
+08/08/2025;GROCERIES;abc;-211.50
+`
+
+	mapping := expense.CSVMapping{
+		Date:        "posted",
+		Description: "Details",
+		Amount:      "3",
+		DateLayout:  "02/01/2006",
+		Negate:      true,
+		Comma:       ';',
+	}
+
+	var expenseList expense.ExpenseList
+	rejected, err := expenseList.ImportCSV(strings.NewReader(statement), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRejected := []expense.RejectedRow{
+		{Line: 4, Reason: `invalid date "2025-08-05": expected 02/01/2006`},
+		{Line: 5, Reason: "description is empty"},
+		{Line: 6, Reason: "negative amount"},
+		{Line: 7, Reason: `invalid amount "abc"`},
+	}
+	if fmt.Sprint(rejected) != fmt.Sprint(expectedRejected) {
+		t.Errorf("expected rejected rows %v, but got %v instead", expectedRejected, rejected)
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%s\n", 1, "2025-08-03", "starbucks coffee", "uncategorized", "$4.50"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%s\n", 2, "2025-08-04", "rent", "uncategorized", "$1200.00"))
+
+	var gotBuf bytes.Buffer
+	expenseList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	// Assert that an amount with a decimal comma is rejected rather than read
+	// as a whole number when the decimal separator is a point.
+	rejected, err = expenseList.ImportCSV(strings.NewReader("Posted;Details;Debit\n09/08/2025;BAKERY;-12,50\n"), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRejected = []expense.RejectedRow{{Line: 2, Reason: `invalid amount "-12,50": ambiguous ",", check the decimal separator`}}
+	if fmt.Sprint(rejected) != fmt.Sprint(expectedRejected) {
+		t.Errorf("expected rejected rows %v, but got %v instead", expectedRejected, rejected)
+	}
+
+	// Assert that a missing required column fails the whole import.
+	if _, err := expenseList.ImportCSV(strings.NewReader(statement), expense.CSVMapping{Comma: ';'}); err == nil {
+		t.Error("expected an error for a missing column, but got nil instead")
+	}
+}
+
+func TestImportDecimalCommaCSV(t *testing.T) {
+	statement := `Datum;Omschrijving;Bedrag
+03-08-2025;Bakker;12,50
+04-08-2025;Huur;1.200,00
+05-08-2025;Markt;1.2
+`
+
+	mapping := expense.CSVMapping{
+		Date:        "datum",
+		Description: "omschrijving",
+		Amount:      "bedrag",
+		DateLayout:  "02-01-2006",
+		Comma:       ';',
+		Decimal:     ',',
+	}
+
+	var expenseList expense.ExpenseList
+	rejected, err := expenseList.ImportCSV(strings.NewReader(statement), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRejected := []expense.RejectedRow{{Line: 4, Reason: `invalid amount "1.2": ambiguous ".", check the decimal separator`}}
+	if fmt.Sprint(rejected) != fmt.Sprint(expectedRejected) {
+		t.Errorf("expected rejected rows %v, but got %v instead", expectedRejected, rejected)
+	}
+
+	if totals := expenseList.Totals(); totals.Total != 1212_50 {
+		t.Errorf("expected a total of %s, but got %s instead", expense.Money(1212_50), totals.Total)
+	}
+
+	// Assert that an invalid decimal separator fails the whole import.
+	var input *expense.InputError
+	if _, err := expenseList.ImportCSV(strings.NewReader(statement), expense.CSVMapping{Comma: ';', Decimal: ' '}); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for an invalid decimal separator, but got %v instead", err)
+	}
+}
+
+func TestImportSplitCSV(t *testing.T) {
+	statement := `date,description,amount,parts
+2025-08-01,supermarket,10.00,groceries=6;household=4
+2025-08-02,pharmacy,10.00,health=6;household=5
+2025-08-03,bakery,3.00,groceries
+`
+
+	var expenseList expense.ExpenseList
+	rejected, err := expenseList.ImportCSV(strings.NewReader(statement), expense.CSVMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRejected := []expense.RejectedRow{
+		{Line: 3, Reason: "parts add up to $11.00 instead of the $10.00 of expense 2"},
+		{Line: 4, Reason: `invalid part "groceries": expected category=amount`},
+	}
+	if fmt.Sprint(rejected) != fmt.Sprint(expectedRejected) {
+		t.Errorf("expected rejected rows %v, but got %v instead", expectedRejected, rejected)
+	}
+
+	expected := []expense.Group{{Name: "groceries", Total: 6_00}, {Name: "household", Total: 4_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) || len(expenseList) != 1 {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+}
@@ -0,0 +1,186 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// DefaultCurrency is the currency of expenses recorded without a currency.
+const DefaultCurrency = "USD"
+
+// currencySymbols maps the currency codes that have a well known symbol to
+// that symbol. Other currencies are displayed with their code.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+}
+
+// normalizeCurrency returns the upper case form of the ISO 4217 style currency
+// code, or DefaultCurrency if the code is empty. It returns an error if the code
+// is not made up of three letters.
+func normalizeCurrency(code string) (string, error) {
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+
+	code = strings.ToUpper(code)
+	if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return "", inputErrorf("invalid currency %q: expected a three letter code", code)
+	}
+	return code, nil
+}
+
+// formatAmount returns the amount prefixed with the symbol of the currency,
+// such as "$12.50", or with its code, such as "NGN 12.50". The sign of a
+// negative amount comes first, such as "-$12.50".
+func formatAmount(amount Money, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + amount.String()
+	}
+	return sign + currency + " " + amount.String()
+}
+
+// rate represents the exchange rate from one currency to another, such that
+// one unit of From is worth Rate units of To.
+type rate struct {
+	From string `json:"from"` // Currency converted from
+	To   string `json:"to"`   // Currency converted to
+	Rate string `json:"rate"` // Decimal exchange rate
+}
+
+// RateList represents a table of exchange rates.
+type RateList []rate
+
+// Load reads exchange rate data from the specified file and loads it into the RateList.
+// It returns an error if there is any issue reading or parsing the file.
+func (r *RateList) Load(filename string) error {
+	return loadJSON(filename, r)
+}
+
+// Save serializes the RateList to JSON format and writes it to the specified file.
+func (r *RateList) Save(filename string) error {
+	return saveJSON(filename, r)
+}
+
+// Set sets the exchange rate from one currency to another, replacing any existing
+// rate between the two currencies. It returns an error if a currency code is invalid,
+// the currencies are the same or the rate is not a positive decimal number.
+func (r *RateList) Set(from, to, value string) error {
+	from, to, err := normalizePair(from, to)
+	if err != nil {
+		return err
+	}
+
+	parsed, ok := new(big.Rat).SetString(value)
+	if !ok || strings.Contains(value, "/") || parsed.Sign() <= 0 {
+		return inputErrorf("invalid rate %q: rate must be a positive decimal number", value)
+	}
+
+	// Drop any rate between the two currencies in either direction, so that
+	// a conversion can never find two conflicting rates.
+	*r = slices.DeleteFunc(*r, func(item rate) bool { return item.matches(from, to) || item.matches(to, from) })
+	*r = append(*r, rate{From: from, To: to, Rate: value})
+	slices.SortFunc(*r, func(x, y rate) int { return strings.Compare(x.From+x.To, y.From+y.To) })
+	return nil
+}
+
+// Remove removes the exchange rate between the two currencies. It returns a
+// *NotFoundError if no such rate exists.
+func (r *RateList) Remove(from, to string) error {
+	from, to, err := normalizePair(from, to)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(*r, func(item rate) bool { return item.matches(from, to) || item.matches(to, from) })
+	if index < 0 {
+		return notFoundf("rate not found: no rate from %s to %s", from, to)
+	}
+
+	*r = slices.Delete(*r, index, index+1)
+	return nil
+}
+
+// List writes the exchange rates to the provided io.Writer in a tabular format.
+func (r *RateList) List(w io.Writer) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-8s%-8s%s\n", "From", "To", "Rate"))
+
+	for _, item := range *r {
+		buf.WriteString(fmt.Sprintf("%-8s%-8s%s\n", item.From, item.To, item.Rate))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// Convert converts the amount from one currency to another, rounding to the
+// nearest cent. A rate set in the opposite direction is used inverted.
+// It returns an error if there is no rate between the two currencies.
+func (r *RateList) Convert(amount Money, from, to string) (Money, error) {
+	from, err := normalizeCurrency(from)
+	if err != nil {
+		return 0, err
+	}
+	to, err = normalizeCurrency(to)
+	if err != nil {
+		return 0, err
+	}
+	if from == to {
+		return amount, nil
+	}
+
+	for _, item := range *r {
+		if !item.matches(from, to) && !item.matches(to, from) {
+			continue
+		}
+
+		factor, ok := new(big.Rat).SetString(item.Rate)
+		if !ok || factor.Sign() <= 0 {
+			return 0, fmt.Errorf("invalid rate %q from %s to %s", item.Rate, item.From, item.To)
+		}
+		if item.matches(to, from) {
+			factor.Inv(factor)
+		}
+
+		cents := new(big.Rat).SetInt64(int64(amount))
+		return roundCents(cents.Mul(cents, factor))
+	}
+
+	return 0, fmt.Errorf("no exchange rate from %s to %s", from, to)
+}
+
+// matches reports whether the rate converts from one currency to the other.
+func (r rate) matches(from, to string) bool {
+	return r.From == from && r.To == to
+}
+
+// normalizePair normalizes both currency codes of an exchange rate and checks
+// that they differ.
+func normalizePair(from, to string) (string, string, error) {
+	if from == "" || to == "" {
+		return "", "", inputErrorf("invalid rate: both currencies are required")
+	}
+
+	from, err := normalizeCurrency(from)
+	if err != nil {
+		return "", "", err
+	}
+	to, err = normalizeCurrency(to)
+	if err != nil {
+		return "", "", err
+	}
+	if from == to {
+		return "", "", inputErrorf("invalid rate: %s cannot be converted to itself", from)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,184 @@
+package expense_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestRateSaveAndLoad(t *testing.T) {
+	// Create a temp file for holding the exchange rates.
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var rateList expense.RateList
+	if err := rateList.Set("eur", "usd", "1.08"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Save the exchange rates into the tempFile.
+	if err := rateList.Save(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var newRateList expense.RateList
+
+	// Read new exchange rates from the tempFile.
+	if err := newRateList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-8s%-8s%s\n", "From", "To", "Rate"))
+	expectedBuf.WriteString(fmt.Sprintf("%-8s%-8s%s\n", "EUR", "USD", "1.08"))
+
+	var gotBuf bytes.Buffer
+	newRateList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestRateSetAndRemove(t *testing.T) {
+	var rateList expense.RateList
+
+	testCases := []struct {
+		from, to, rate string
+	}{
+		{from: "EUR", to: "USD", rate: "0"},
+		{from: "EUR", to: "USD", rate: "abc"},
+		{from: "EUR", to: "EUR", rate: "1"},
+		{from: "EURO", to: "USD", rate: "1.08"},
+		{from: "", to: "USD", rate: "1.08"},
+	}
+
+	for _, tc := range testCases {
+		if err := rateList.Set(tc.from, tc.to, tc.rate); err == nil {
+			t.Errorf("expected an error for %s->%s at %s, but got nil instead", tc.from, tc.to, tc.rate)
+		}
+	}
+
+	// Setting the inverse rate replaces the existing one.
+	if err := rateList.Set("EUR", "USD", "1.08"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rateList.Set("USD", "EUR", "0.9"); err != nil {
+		t.Fatal(err)
+	}
+	if len(rateList) != 1 {
+		t.Errorf("expected length of the rate list: %d, but got %d instead", 1, len(rateList))
+	}
+
+	if err := rateList.Remove("eur", "usd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rateList.Remove("EUR", "USD"); err == nil {
+		t.Error("expected an error for a missing rate, but got nil instead")
+	}
+}
+
+func TestRateConvert(t *testing.T) {
+	var rateList expense.RateList
+	if err := rateList.Set("EUR", "USD", "1.08"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		amount   expense.Money
+		from, to string
+		expected expense.Money
+		wantErr  bool
+	}{
+		{name: "Direct", amount: 12_50, from: "EUR", to: "USD", expected: 13_50},
+		{name: "Inverse", amount: 10_00, from: "USD", to: "EUR", expected: 9_26},
+		{name: "Same", amount: 10_00, from: "GBP", to: "gbp", expected: 10_00},
+		{name: "Missing", amount: 10_00, from: "GBP", to: "USD", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rateList.Convert(tc.amount, tc.from, tc.to)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, but got %s instead", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %s, but got %s instead", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpenseListConvert(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var rateList expense.RateList
+
+	if err := rateList.Set("EUR", "USD", "1.10"); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 1", 10_00, "", "usd", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 2", 20_00, "", "EUR", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert every expense is listed in its own currency.
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%s\n", 1, time.Now().Format("2006-01-02"), "demo expense 1", "uncategorized", "$10.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%s\n", 2, time.Now().Format("2006-01-02"), "demo expense 2", "uncategorized", "€20.00"))
+
+	var gotBuf bytes.Buffer
+	expenseList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	// Assert the summary is reported in the converted currency.
+	converted, err := expenseList.Convert(rateList, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Total expenses: €29.09\n"
+
+	var buf bytes.Buffer
+	converted.Summary(&buf)
+
+	if expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+
+	// Assert totals are written in the currency they carry, even without expenses.
+	var emptyList expense.ExpenseList
+	totals := emptyList.Totals()
+	totals.Currency = "EUR"
+
+	buf.Reset()
+	totals.WriteSummary(&buf, false)
+	if expected := "Total expenses: €0.00\n"; expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+
+	// Assert converting without a rate fails.
+	if _, err := expenseList.Convert(rateList, "GBP"); err == nil {
+		t.Error("expected an error for a missing rate, but got nil instead")
+	}
+}
@@ -13,26 +13,168 @@ import (
 	"time"
 )
 
-// expense represents a single expense entry with an ID, date, description, and amount.
+// uncategorized is the category reported for expenses without a category.
+const uncategorized = "uncategorized"
+
+// Kinds of the records of an ExpenseList. Records without a kind are expenses.
+const (
+	KindExpense = "expense"
+	KindIncome  = "income"
+	KindRefund  = "refund"
+)
+
+// expense represents a single expense entry with an ID, date, description, category, amount and currency.
 type expense struct {
-	ID          int       `json:"id"`          // Unique identifier for the expense
-	Date        time.Time `json:"date"`        // Date when the expense was incurred
-	Description string    `json:"description"` // Description of the expense
-	Amount      float64   `json:"amount"`      // Amount of the expense
+	ID          int       `json:"id"`                     // Unique identifier for the expense
+	Date        time.Time `json:"date"`                   // Date when the expense was incurred
+	Description string    `json:"description"`            // Description of the expense
+	Category    string    `json:"category,omitempty"`     // Category of the expense
+	Amount      Money     `json:"amount"`                 // Amount of the expense
+	Currency    string    `json:"currency,omitempty"`     // Currency of the amount
+	Tags        []string  `json:"tags,omitempty"`         // Free-form labels of the expense, such as billable
+	Account     string    `json:"account,omitempty"`      // Payment account the expense was paid from
+	Kind        string    `json:"kind,omitempty"`         // KindIncome for income, KindRefund for a refund, empty for an expense
+	RefundOf    int       `json:"refund_of,omitempty"`    // ID of the expense a refund was made for
+	Parts       []Part    `json:"parts,omitempty"`        // Line items of a split expense, adding up to its amount
+	RecurringID int       `json:"recurring_id,omitempty"` // ID of the recurring rule that added the expense
+	CreatedAt   time.Time `json:"created_at,omitzero"`    // Date and time the expense was recorded
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`    // Date and time the expense was last modified
+	DeletedAt   time.Time `json:"deleted_at,omitzero"`    // Date and time the expense was moved to the trash
 }
 
 // String returns the string representation of expense struct.
 func (e expense) String() string {
-	return fmt.Sprintf("%-6d%-14s%-70s$%.2f", e.ID, e.Date.Format("2006-01-02"), e.Description, e.Amount)
+	return fmt.Sprintf("%-6d%-14s%-70s%-20s%s", e.ID, e.Date.Format("2006-01-02"), e.Description, e.categoryName(), e.formattedAmount())
+}
+
+// income reports whether the record is income rather than an expense.
+func (e expense) income() bool {
+	return e.Kind == KindIncome
+}
+
+// refund reports whether the record is a refund of an expense.
+func (e expense) refund() bool {
+	return e.Kind == KindRefund
+}
+
+// kindName returns the kind of the record, KindExpense, KindIncome or KindRefund.
+func (e expense) kindName() string {
+	if e.Kind == "" {
+		return KindExpense
+	}
+	return e.Kind
+}
+
+// formattedAmount returns the amount of the record with the symbol of its
+// currency, preceded by a plus sign for income and refunds, such as "+$2500.00".
+func (e expense) formattedAmount() string {
+	if e.income() || e.refund() {
+		return "+" + formatAmount(e.Amount, e.currencyCode())
+	}
+	return formatAmount(e.Amount, e.currencyCode())
+}
+
+// currencyCode returns the currency of the expense, or DefaultCurrency if the
+// expense was recorded without a currency.
+func (e expense) currencyCode() string {
+	if e.Currency == "" {
+		return DefaultCurrency
+	}
+	return e.Currency
+}
+
+// categoryName returns the category of the expense, or "uncategorized" if
+// the expense has no category.
+func (e expense) categoryName() string {
+	if e.Category == "" {
+		return uncategorized
+	}
+	return e.Category
+}
+
+// deleted reports whether the expense is in the trash.
+func (e expense) deleted() bool {
+	return !e.DeletedAt.IsZero()
 }
 
-// ExpenseList represents a list of expenses.
+// ExpenseList represents a list of expenses. Deleted expenses are kept in
+// the list, in the trash, until the trash is emptied.
 type ExpenseList []expense
 
+// NotFoundError is returned when no expense in an ExpenseList has the requested
+// ID, or when something else that was looked up, such as an account or a
+// budget, does not exist.
+type NotFoundError struct {
+	ID      int    // ID of the expense that was looked up
+	Message string // Description of what was not found, if it is not an expense
+}
+
+// Error returns the message of the NotFoundError.
+func (e *NotFoundError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("expense not found: no expense with ID %d", e.ID)
+}
+
+// notFoundf returns a *NotFoundError with the message formatted according to
+// the format specifier.
+func notFoundf(format string, args ...any) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...)}
+}
+
+// InputError is returned when a value supplied by the user is invalid, such as
+// an empty description, a negative amount or a malformed currency code.
+type InputError struct {
+	Message string // Description of what is wrong with the value
+}
+
+// Error returns the message of the InputError.
+func (e *InputError) Error() string {
+	return e.Message
+}
+
+// inputErrorf returns an *InputError with the message formatted according to
+// the format specifier.
+func inputErrorf(format string, args ...any) error {
+	return &InputError{Message: fmt.Sprintf(format, args...)}
+}
+
+// currency returns the currency the totals of the ExpenseList are reported in,
+// which is the currency of its first expense. Lists holding several currencies
+// should be converted with Convert before they are summarized.
+func (e *ExpenseList) currency() string {
+	if len(*e) == 0 {
+		return DefaultCurrency
+	}
+	return (*e)[0].currencyCode()
+}
+
 // Load reads expense data from the specified file and loads it into the ExpenseList.
 // The filename parameter specifies the path to the file to be loaded.
 // It returns an error if there is any issue reading or parsing the file.
 func (e *ExpenseList) Load(filename string) error {
+	return loadJSON(filename, e)
+}
+
+// Save serializes the ExpenseList to JSON format and writes it to the specified file.
+// The JSON data is indented for readability.
+// The file is replaced atomically, so a failed save leaves the previous contents intact,
+// and the previous version is kept next to it with the ".bak" suffix.
+// The file is created with read-write permissions for the owner and read-only permissions for others.
+//
+// Parameters:
+//   - filename: The name of the file where the JSON data will be saved.
+//
+// Returns:
+//   - error: An error if the JSON marshaling or file writing fails, otherwise nil.
+func (e *ExpenseList) Save(filename string) error {
+	return saveJSON(filename, e)
+}
+
+// loadJSON reads the specified file and parses its JSON contents into v.
+// A missing or empty file is not an error and leaves v untouched.
+func loadJSON(filename string, v any) error {
 	// Read the contents of the file using os.ReadFile
 	content, err := os.ReadFile(filename)
 	if err != nil {
@@ -50,129 +192,269 @@ func (e *ExpenseList) Load(filename string) error {
 		return nil
 	}
 
-	// Parse the json contents into list of expense struct.
-	return json.Unmarshal(content, e)
+	// Parse the json contents into v.
+	return json.Unmarshal(content, v)
 }
 
-// Save serializes the ExpenseList to JSON format and writes it to the specified file.
-// The JSON data is indented for readability.
-// The file is created with read-write permissions for the owner and read-only permissions for others.
-//
-// Parameters:
-//   - filename: The name of the file where the JSON data will be saved.
-//
-// Returns:
-//   - error: An error if the JSON marshaling or file writing fails, otherwise nil.
-func (e *ExpenseList) Save(filename string) error {
-	js, err := json.MarshalIndent(e, "", "\t")
+// saveJSON serializes v to indented JSON and atomically writes it to the specified
+// file, keeping the previous version of the file as a backup.
+func saveJSON(filename string, v any) error {
+	js, err := json.MarshalIndent(v, "", "\t")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, js, 0644)
+	return writeFileAtomic(filename, js, 0644)
 }
 
-// Add adds a new expense to the ExpenseList with the given description and amount.
-// It returns an error if the description is empty or the amount is negative.
+// Add adds a new expense to the ExpenseList with the given description, amount, category, currency and date.
+// It returns an error if the description is empty, the amount is negative or the currency is invalid.
 //
 // Parameters:
 //   - description: A string representing the description of the expense.
-//   - amount: A float64 representing the amount of the expense.
+//   - amount: A Money value representing the amount of the expense.
+//   - category: A string representing the category of the expense. It may be empty.
+//   - currency: A three letter currency code of the amount. If empty, DefaultCurrency is used.
+//   - date: The date the expense was incurred. If zero, the current date and time is used.
 //
 // Returns:
-//   - error: An error if the description is empty or the amount is negative, otherwise nil.
-func (e *ExpenseList) Add(description string, amount float64) error {
+//   - error: An error if the description is empty, the amount is negative or the currency is invalid, otherwise nil.
+func (e *ExpenseList) Add(description string, amount Money, category, currency string, date time.Time) error {
 	if description == "" {
-		return errors.New("description is empty")
+		return inputErrorf("description is empty")
 	}
 
 	if amount < 0 {
-		return errors.New("negative amount")
+		return inputErrorf("negative amount")
 	}
 
-	expenseList := *e
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+
+	// Calculate the next id by adding the highest expense id + 1
+	id := 1
+	for _, item := range *e {
+		id = max(id, item.ID+1)
+	}
 
-	// Calculate the next id by adding the last expense id + 1
-	var id int
-	if len(expenseList) == 0 {
-		id = 1
-	} else {
-		id = expenseList[len(expenseList)-1].ID + 1
+	now := time.Now()
+	if date.IsZero() {
+		date = now
 	}
 
 	item := expense{
 		ID:          id,
-		Date:        time.Now(),
+		Date:        date,
 		Description: strings.ToLower(description),
+		Category:    strings.ToLower(category),
 		Amount:      amount,
+		Currency:    currency,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
 	*e = append(*e, item)
 	return nil
 }
 
-// Update modifies the description and/or amount of an expense item in the ExpenseList.
-// The expense item to be updated is identified by its id (1-based index).
-// If a new non-empty description is provided, it updates the description and sets the current date and time.
-// If a new non-negative amount is provided, it updates the amount and sets the current date and time.
-// Returns an error if the provided position is out of range.
+// Update modifies the description, amount, category and/or date of an expense item in the ExpenseList.
+// The expense item to be updated is identified by its ID. The refunds of an expense
+// follow its new date and category, see Refunds. The amount of a split expense cannot
+// be changed until its split is removed, see Split.
+// If a new non-empty description is provided, it updates the description.
+// If a new non-negative amount is provided, it updates the amount.
+// If a new non-empty category is provided, it updates the category.
+// If a new non-zero date is provided, it updates the date the expense was incurred.
+// The modification time of the expense is set to the current date and time if anything changed.
+// Returns a *NotFoundError if no expense outside the trash has the provided ID, or an
+// *InputError if the date or category of a refund is changed, the amount of a refund
+// is zero, the amount of a split expense is changed or the amount would leave an
+// expense refunded for more than it cost.
 //
 // Parameters:
-//   - pos: The 1-based position of the expense to be updated.
+//   - id: The ID of the expense to be updated.
 //   - description: The new description for the expense item. If empty, the description is not updated.
 //   - amount: The new amount for the expense item. If negative, the amount is not updated.
+//   - category: The new category for the expense item. If empty, the category is not updated.
+//   - date: The new date for the expense item. If zero, the date is not updated.
 //
 // Returns:
-//   - error: An error if the provided position is out of range, otherwise nil.
-func (e *ExpenseList) Update(pos int, description string, amount float64) error {
-	expenseList := *e
+//   - error: An error if no expense has the provided ID, otherwise nil.
+func (e *ExpenseList) Update(id int, description string, amount Money, category string, date time.Time) error {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return err
+	}
+	item := &(*e)[index]
+	changed := false
 
-	// Check if the provided id is within the range.
-	if pos < 1 || pos > len(expenseList) {
-		return errors.New("invalid position: position is out of range")
+	// The date and category of a refund are those of its expense, and an
+	// expense cannot be refunded for more than it cost.
+	if item.refund() && (category != "" || !date.IsZero()) {
+		return inputErrorf("the date and category of refund %d follow expense %d", id, item.RefundOf)
+	}
+	if amount == 0 && item.refund() {
+		return inputErrorf("refund amount must be positive")
+	}
+	if amount >= 0 && item.refund() {
+		original, err := e.Get(item.RefundOf)
+		if err == nil && amount > original.Amount-e.refunded(item.RefundOf)+item.Amount {
+			return inputErrorf("refund of %s exceeds the amount of expense %d", formatAmount(amount, item.currencyCode()), item.RefundOf)
+		}
+	}
+	if amount >= 0 && amount != item.Amount && len(item.Parts) > 0 && !item.refund() {
+		return inputErrorf("expense %d is split: remove the split before changing its amount", id)
+	}
+	if amount >= 0 && amount < e.refunded(id) {
+		return inputErrorf("amount of %s is less than the %s refunded", formatAmount(amount, item.currencyCode()), formatAmount(e.refunded(id), item.currencyCode()))
 	}
 
 	// Update description only if new non-empty description is provided.
-	if description != "" && expenseList[pos-1].Description != strings.ToLower(description) {
-		expenseList[pos-1].Description = strings.ToLower(description)
-		expenseList[pos-1].Date = time.Now()
+	if description != "" && item.Description != strings.ToLower(description) {
+		item.Description = strings.ToLower(description)
+		changed = true
 	}
 
 	// Update amount only if new non-negative amount is provided.
-	if amount >= 0 && expenseList[pos-1].Amount != amount {
-		expenseList[pos-1].Amount = amount
-		expenseList[pos-1].Date = time.Now()
+	if amount >= 0 && item.Amount != amount {
+		item.Amount = amount
+		if item.refund() {
+			original, _ := e.Get(item.RefundOf)
+			item.Parts = apportion(amount, original.Parts)
+		}
+		changed = true
+	}
+
+	// Update category only if new non-empty category is provided.
+	if category != "" && item.Category != strings.ToLower(category) {
+		item.Category = strings.ToLower(category)
+		changed = true
+	}
+
+	// Update date only if new non-zero date is provided.
+	if !date.IsZero() && !item.Date.Equal(date) {
+		item.Date = date
+		changed = true
+	}
+
+	// Record when the expense was last modified, and move its refunds along with it.
+	if changed {
+		item.UpdatedAt = time.Now()
+		e.syncRefunds(*item)
 	}
 
 	return nil
 }
 
-// Delete removes an expense from the ExpenseList at the specified position.
-// The position is 1-based, meaning the first element is at position 1.
-// If the position is out of range, it returns an error indicating an invalid position.
+// Delete moves the expense with the specified ID to the trash, where it is
+// hidden from the list and the summaries until it is restored or the trash is
+// emptied. If no expense outside the trash has the ID, it returns a *NotFoundError,
+// and if the expense has refunds outside the trash, an *InputError.
 //
 // Parameters:
-// - pos: The 1-based position of the expense to be removed.
+// - id: The ID of the expense to be deleted.
 //
 // Returns:
-// - error: An error if the position is out of range, otherwise nil.
-func (e *ExpenseList) Delete(pos int) error {
-	expenseList := *e
-	if pos < 1 || pos > len(expenseList) {
-		return errors.New("invalid position: position is out of range")
+// - error: An error if no expense has the provided ID, otherwise nil.
+func (e *ExpenseList) Delete(id int) error {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return err
+	}
+	if len(e.Refunds(id)) > 0 {
+		return inputErrorf("expense %d has refunds: delete them first", id)
 	}
 
-	*e = slices.Delete(expenseList, pos-1, pos)
+	(*e)[index].DeletedAt = time.Now()
+	return nil
+}
+
+// Get returns the expense with the specified ID.
+// If no expense outside the trash has the ID, it returns a *NotFoundError.
+func (e *ExpenseList) Get(id int) (expense, error) {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return expense{}, err
+	}
+	return (*e)[index], nil
+}
+
+// indexOf returns the position in the ExpenseList of the expense with the
+// specified ID, in the trash or not, or a *NotFoundError if no expense has the ID.
+func (e *ExpenseList) indexOf(id int) (int, error) {
+	index := slices.IndexFunc(*e, func(item expense) bool { return item.ID == id })
+	if index < 0 {
+		return 0, &NotFoundError{ID: id}
+	}
+	return index, nil
+}
+
+// activeIndexOf returns the position in the ExpenseList of the expense with the
+// specified ID, or a *NotFoundError if no expense outside the trash has the ID.
+func (e *ExpenseList) activeIndexOf(id int) (int, error) {
+	index, err := e.indexOf(id)
+	if err != nil {
+		return 0, err
+	}
+	if (*e)[index].deleted() {
+		return 0, &NotFoundError{ID: id}
+	}
+	return index, nil
+}
+
+// active returns a new ExpenseList with the expenses that are not in the trash.
+func (e *ExpenseList) active() ExpenseList {
+	return e.filter(func(item expense) bool { return !item.deleted() })
+}
+
+// spending returns a new ExpenseList with the expenses that are not in the
+// trash, leaving out income. Refunds are included with negative amounts, so
+// that they are netted against the category and month of their expense.
+func (e *ExpenseList) spending() ExpenseList {
+	spending := e.filter(func(item expense) bool { return !item.deleted() && !item.income() })
+	for index, item := range spending {
+		if !item.refund() {
+			continue
+		}
+		spending[index].Amount = -item.Amount
+		spending[index].Parts = make([]Part, len(item.Parts))
+		for i, part := range item.Parts {
+			spending[index].Parts[i] = Part{Category: part.Category, Amount: -part.Amount}
+		}
+	}
+	return spending
+}
+
+// HasIncome reports whether any record outside the trash is income.
+func (e *ExpenseList) HasIncome() bool {
+	return slices.ContainsFunc(*e, func(item expense) bool { return !item.deleted() && item.income() })
+}
+
+// AddIncome adds income with the given description, amount, category, currency
+// and date to the ExpenseList. Income shares the IDs of the expenses and is
+// validated like them, but it is left out of the totals of expenses and budgets
+// and reported as income by the summaries. See Add for the parameters.
+func (e *ExpenseList) AddIncome(description string, amount Money, category, currency string, date time.Time) error {
+	if err := e.Add(description, amount, category, currency, date); err != nil {
+		return err
+	}
+	(*e)[len(*e)-1].Kind = KindIncome
 	return nil
 }
 
 // List writes the expense list to the provided io.Writer in a tabular format.
 func (e *ExpenseList) List(w io.Writer) {
-	header := fmt.Sprintf("%-6s%-14s%-70s%s\n", "ID", "Date", "Description", "Amount")
+	header := fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount")
 	var buf bytes.Buffer
 	buf.WriteString(header)
 
-	for index, item := range *e {
-		buf.WriteString(fmt.Sprintf("%-6d%-14s%-70s$%.2f\n", index+1, item.Date.Format("2006-01-02"), item.Description, item.Amount))
+	for _, item := range e.active() {
+		buf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%s\n", item.ID, item.Date.Format("2006-01-02"), item.Description, item.categoryName(), item.formattedAmount()))
+		for _, part := range item.Parts {
+			line := expense{Amount: part.Amount, Currency: item.Currency, Kind: item.Kind}
+			buf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "", "", "  part", part.categoryName(), line.formattedAmount()))
+		}
 	}
 
 	w.Write(buf.Bytes())
@@ -180,48 +462,147 @@ func (e *ExpenseList) List(w io.Writer) {
 
 // Summary writes a summary of the total expenses to the provided io.Writer.
 // It calculates the total amount of all expenses in the ExpenseList and
-// writes the formatted summary string to the output writer.
+// writes the formatted summary string to the output writer. If the ExpenseList
+// holds income, the total income and the net amount are written after it.
 //
 // Parameters:
 //
 //	w (io.Writer): The writer to which the summary will be written.
 func (e *ExpenseList) Summary(w io.Writer) {
-	var total float64 = 0
-
-	for _, item := range *e {
-		total += item.Amount
-	}
-
-	summary := fmt.Sprintf("Total expenses: $%.2f\n", total)
-	w.Write([]byte(summary))
+	e.Totals().WriteSummary(w, e.HasIncome())
 }
 
-// SummaryForMonth writes a summary of the total expenses for a given month to the provided writer.
+// SummaryForMonth writes a summary of the total expenses for a given month of a given year to the provided writer.
 // The month parameter should be an integer between 1 and 12, representing the months January to December.
 // If the month is out of range, an error is returned.
-// The summary includes the total amount of expenses for the specified month.
+// The summary includes the total amount of expenses for the specified month, and
+// the total income and the net amount if the ExpenseList holds income.
 //
 // Parameters:
 //
 //	w - an io.Writer where the summary will be written
+//	year - the year the month belongs to
 //	month - an integer representing the month (1 for January, 12 for December)
 //
 // Returns:
 //
 //	error - an error if the month is out of range, otherwise nil
-func (e *ExpenseList) SummaryForMonth(w io.Writer, month int) error {
-	if month < 1 || month > 12 {
-		return errors.New("invalid month: month is out of range")
+func (e *ExpenseList) SummaryForMonth(w io.Writer, year, month int) error {
+	totals, err := e.TotalsForMonth(year, month)
+	if err != nil {
+		return err
 	}
 
-	var total float64 = 0
-	for _, item := range *e {
-		if item.Date.Month() == time.Month(month) {
+	totals.WriteSummary(w, e.HasIncome())
+	return nil
+}
+
+// SummaryForYear writes a summary of the total expenses for a given year to the provided writer,
+// followed by the total income and the net amount if the ExpenseList holds income.
+//
+// Parameters:
+//
+//	w - an io.Writer where the summary will be written
+//	year - the year to summarize
+func (e *ExpenseList) SummaryForYear(w io.Writer, year int) {
+	e.TotalsForYear(year).WriteSummary(w, e.HasIncome())
+}
+
+// SummaryByMonth writes the total expenses of every month of a given year to the
+// provided io.Writer in a tabular format, followed by the total for the year.
+//
+// Parameters:
+//
+//	w - an io.Writer where the summary will be written
+//	year - the year to summarize
+func (e *ExpenseList) SummaryByMonth(w io.Writer, year int) {
+	e.TotalsByMonth(year).WriteTable(w, "Month")
+}
+
+// SummaryByCategory writes the total expenses grouped by category to the provided
+// io.Writer in a tabular format, followed by the grand total. Categories are sorted
+// alphabetically and expenses without a category are reported as "uncategorized".
+//
+// Parameters:
+//
+//	w (io.Writer): The writer to which the summary will be written.
+func (e *ExpenseList) SummaryByCategory(w io.Writer) {
+	e.TotalsByCategory().WriteTable(w, "Category")
+}
+
+// spent returns the total amount of the expenses incurred in the given month of
+// the given year. If category is not empty, only expenses in that category, and
+// the parts of split expenses in that category, are counted.
+func (e *ExpenseList) spent(year int, month time.Month, category string) Money {
+	var total Money = 0
+	for _, item := range e.spending() {
+		if item.Date.Year() != year || item.Date.Month() != month {
+			continue
+		}
+		if category == "" {
 			total += item.Amount
+			continue
+		}
+		for _, part := range item.categoryParts() {
+			if part.categoryName() == category {
+				total += part.Amount
+			}
 		}
 	}
+	return total
+}
 
-	summary := fmt.Sprintf("Total expenses for %s: $%.2f\n", time.Month(month).String(), total)
-	w.Write([]byte(summary))
-	return nil
+// Convert returns a copy of the ExpenseList with the amount of every expense
+// converted into the given currency using the exchange rates.
+// It returns an error if an expense cannot be converted.
+func (e *ExpenseList) Convert(rates RateList, currency string) (ExpenseList, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make(ExpenseList, 0, len(*e))
+	for _, item := range e.active() {
+		amount, err := rates.Convert(item.Amount, item.currencyCode(), currency)
+		if err != nil {
+			return nil, fmt.Errorf("expense %d: %w", item.ID, err)
+		}
+
+		item.Amount = amount
+		item.Currency = currency
+		item.Parts = apportion(amount, item.Parts)
+		converted = append(converted, item)
+	}
+
+	return converted, nil
+}
+
+// Currencies returns the currencies of the expenses outside the trash, sorted
+// alphabetically.
+func (e *ExpenseList) Currencies() []string {
+	currencies := []string{}
+	for _, item := range e.active() {
+		if !slices.Contains(currencies, item.currencyCode()) {
+			currencies = append(currencies, item.currencyCode())
+		}
+	}
+	slices.Sort(currencies)
+	return currencies
+}
+
+// Between returns a new ExpenseList with the expenses incurred on or after from
+// and before to. A zero from or to leaves that end of the range open.
+func (e *ExpenseList) Between(from, to time.Time) ExpenseList {
+	return e.filter(Query{From: from, To: to}.matches)
+}
+
+// filter returns a new ExpenseList with the expenses for which keep returns true.
+func (e *ExpenseList) filter(keep func(item expense) bool) ExpenseList {
+	filtered := make(ExpenseList, 0, len(*e))
+	for _, item := range *e {
+		if keep(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
 }
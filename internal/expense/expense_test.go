@@ -2,8 +2,10 @@ package expense_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,7 +23,7 @@ func TestSaveAndLoad(t *testing.T) {
 	var expenseList expense.ExpenseList
 
 	// Add new expense to the list.
-	if err := expenseList.Add("Demo Expense", 50.55); err != nil {
+	if err := expenseList.Add("Demo Expense", 50_55, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -54,40 +56,40 @@ func TestAdd(t *testing.T) {
 
 	testCases := []struct {
 		description string
-		amount      float64
+		amount      expense.Money
 		expected    string
 	}{
 		{
 			description: "Demo Expense 1",
-			amount:      100,
-			expected:    fmt.Sprintf("%-6d%-14s%-70s$%.2f", 1, time.Now().Format("2006-01-02"), "demo expense 1", 100.0),
+			amount:      100_00,
+			expected:    fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 1, time.Now().Format("2006-01-02"), "demo expense 1", "uncategorized", 100.0),
 		},
 
 		{
 			description: "Demo Expense 2",
-			amount:      150,
-			expected:    fmt.Sprintf("%-6d%-14s%-70s$%.2f", 2, time.Now().Format("2006-01-02"), "demo expense 2", 150.0),
+			amount:      150_00,
+			expected:    fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 2, time.Now().Format("2006-01-02"), "demo expense 2", "uncategorized", 150.0),
 		},
 		{
 			description: "Demo Expense 3",
-			amount:      250,
-			expected:    fmt.Sprintf("%-6d%-14s%-70s$%.2f", 3, time.Now().Format("2006-01-02"), "demo expense 3", 250.0),
+			amount:      250_00,
+			expected:    fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 3, time.Now().Format("2006-01-02"), "demo expense 3", "uncategorized", 250.0),
 		},
 		{
 			description: "Demo Expense 4",
-			amount:      1150,
-			expected:    fmt.Sprintf("%-6d%-14s%-70s$%.2f", 4, time.Now().Format("2006-01-02"), "demo expense 4", 1150.0),
+			amount:      1150_00,
+			expected:    fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 4, time.Now().Format("2006-01-02"), "demo expense 4", "uncategorized", 1150.0),
 		},
 		{
 			description: "Demo Expense 5",
-			amount:      50.90,
-			expected:    fmt.Sprintf("%-6d%-14s%-70s$%.2f", 5, time.Now().Format("2006-01-02"), "demo expense 5", 50.90),
+			amount:      50_90,
+			expected:    fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 5, time.Now().Format("2006-01-02"), "demo expense 5", "uncategorized", 50.90),
 		},
 	}
 
 	for index, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			if err := expenseList.Add(tc.description, tc.amount); err != nil {
+			if err := expenseList.Add(tc.description, tc.amount, "", "", time.Time{}); err != nil {
 				t.Fatal(err)
 			}
 
@@ -102,40 +104,61 @@ func TestUpdate(t *testing.T) {
 	var expenseList expense.ExpenseList
 
 	// Add some expenses.
-	if err := expenseList.Add("Demo Expense 1", 100); err != nil {
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 2", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 3", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 3", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
 	// Update the second expense item.
-	if err := expenseList.Update(2, "New Demo Expense 2", 500); err != nil {
+	if err := expenseList.Update(2, "New Demo Expense 2", 500_00, "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
-	expected := fmt.Sprintf("%-6d%-14s%-70s$%.2f", 2, time.Now().Format("2006-01-02"), "new demo expense 2", 500.0)
+	expected := fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 2, time.Now().Format("2006-01-02"), "new demo expense 2", "uncategorized", 500.0)
 
 	// Assert the second expense item was updated successfully.
 	if expenseList[1].String() != expected {
 		t.Errorf("expected %q, but got %q instead", expected, expenseList[1].String())
 	}
+
+	// Delete the first expense item and update the third by its ID.
+	if err := expenseList.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Update(3, "New Demo Expense 3", -1, "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 3, time.Now().Format("2006-01-02"), "new demo expense 3", "uncategorized", 150.0)
+
+	// Assert the expense with ID 3 was updated.
+	if expenseList[2].String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, expenseList[2].String())
+	}
+
+	// Assert that updating a deleted expense returns a not found error.
+	var notFound *expense.NotFoundError
+	if err := expenseList.Update(1, "New Demo Expense 1", -1, "", time.Time{}); !errors.As(err, &notFound) {
+		t.Errorf("expected a not found error, but got %v instead", err)
+	}
 }
 
 func TestDelete(t *testing.T) {
 	var expenseList expense.ExpenseList
 
 	// Add some expenses.
-	if err := expenseList.Add("Demo Expense 1", 100); err != nil {
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 2", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 3", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 3", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -144,41 +167,49 @@ func TestDelete(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Assert that the expense item has been deleted.
-	if len(expenseList) != 2 {
-		t.Errorf("expected length of the expense list: %d, but got %d instead", 2, len(expenseList))
+	// Assert that the expense item has been moved to the trash rather than removed.
+	if len(expenseList) != 3 {
+		t.Errorf("expected length of the expense list: %d, but got %d instead", 3, len(expenseList))
 	}
-
-	// Assert the first is the same.
-	expected := fmt.Sprintf("%-6d%-14s%-70s$%.2f", 1, time.Now().Format("2006-01-02"), "demo expense 1", 100.0)
-	if expenseList[0].String() != expected {
-		t.Errorf("expected %q, but got %q instead", expected, expenseList[0].String())
+	if expenseList[1].DeletedAt.IsZero() {
+		t.Errorf("expected the expense with ID %d to be deleted", 2)
 	}
 
-	// Assert the last expense is the same.
-	expected = fmt.Sprintf("%-6d%-14s%-70s$%.2f", 3, time.Now().Format("2006-01-02"), "demo expense 3", 150.0)
-	if expenseList[1].String() != expected {
-		t.Errorf("expected %q, but got %q instead", expected, expenseList[1].String())
+	// Assert that the deleted expense item can not be looked up or deleted again.
+	var notFound *expense.NotFoundError
+	if _, err := expenseList.Get(2); !errors.As(err, &notFound) || notFound.ID != 2 {
+		t.Errorf("expected a not found error for ID %d, but got %v instead", 2, err)
+	}
+	if err := expenseList.Delete(2); !errors.As(err, &notFound) || notFound.ID != 2 {
+		t.Errorf("expected a not found error for ID %d, but got %v instead", 2, err)
 	}
 
-	// Delete the last expense item.
-	if err := expenseList.Delete(2); err != nil {
-		t.Fatal(err)
+	// Assert that the deleted expense item is hidden from the list and the summary.
+	var buf bytes.Buffer
+	expenseList.List(&buf)
+	expected := fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount") +
+		fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f\n", 1, time.Now().Format("2006-01-02"), "demo expense 1", "uncategorized", 100.0) +
+		fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f\n", 3, time.Now().Format("2006-01-02"), "demo expense 3", "uncategorized", 150.0)
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
 	}
 
-	// Assert that the expense item has been deleted.
-	if len(expenseList) != 1 {
-		t.Errorf("expected length of the expense list: %d, but got %d instead", 1, len(expenseList))
+	buf.Reset()
+	expenseList.Summary(&buf)
+	expected = fmt.Sprintf("Total expenses: $%.2f\n", 250.0)
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
 	}
 
-	// Delete the remaining expense item.
-	if err := expenseList.Delete(1); err != nil {
+	// Assert that a new expense does not reuse the ID of a deleted one.
+	if err := expenseList.Delete(3); err != nil {
 		t.Fatal(err)
 	}
-
-	// Assert the expense list is empty.
-	if len(expenseList) != 0 {
-		t.Errorf("expected length of the expense list: %d, but got %d instead", 0, len(expenseList))
+	if err := expenseList.Add("Demo Expense 4", 10_00, "", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if id := expenseList[len(expenseList)-1].ID; id != 4 {
+		t.Errorf("expected ID %d, but got %d instead", 4, id)
 	}
 }
 
@@ -186,21 +217,21 @@ func TestList(t *testing.T) {
 	var expenseList expense.ExpenseList
 
 	// Add some expenses.
-	if err := expenseList.Add("Demo Expense 1", 100); err != nil {
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 2", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 3", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 3", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
 	var expectedBuf bytes.Buffer
-	expectedBuf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%s\n", "ID", "Date", "Description", "Amount"))
-	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s$%.2f\n", 1, time.Now().Format("2006-01-02"), "demo expense 1", 100.0))
-	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s$%.2f\n", 2, time.Now().Format("2006-01-02"), "demo expense 2", 150.0))
-	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s$%.2f\n", 3, time.Now().Format("2006-01-02"), "demo expense 3", 150.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f\n", 1, time.Now().Format("2006-01-02"), "demo expense 1", "uncategorized", 100.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f\n", 2, time.Now().Format("2006-01-02"), "demo expense 2", "uncategorized", 150.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f\n", 3, time.Now().Format("2006-01-02"), "demo expense 3", "uncategorized", 150.0))
 
 	var gotBuf bytes.Buffer
 
@@ -215,13 +246,13 @@ func TestSummary(t *testing.T) {
 	var expenseList expense.ExpenseList
 
 	// Add some expenses.
-	if err := expenseList.Add("Demo Expense 1", 100); err != nil {
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 2", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 3", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 3", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -235,27 +266,231 @@ func TestSummary(t *testing.T) {
 	}
 }
 
+func TestSummaryWithIncome(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	// Add an expense and some income.
+	if err := expenseList.Add("Rent", 800_00, "housing", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Salary", 3000_00, "work", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Refund", -5_00, "", "", time.Time{}); err == nil {
+		t.Error("expected an error adding income with a negative amount")
+	}
+
+	expected := "Total expenses: $800.00\nTotal income: $3000.00\nNet: $2200.00\n"
+
+	var buf bytes.Buffer
+	expenseList.Summary(&buf)
+
+	if expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+
+	// Income is left out of the totals by category.
+	buf.Reset()
+	expenseList.SummaryByCategory(&buf)
+	if strings.Contains(buf.String(), "work") {
+		t.Errorf("expected no income in the summary by category, but got %q instead", buf.String())
+	}
+}
+
 func TestSummaryForMonth(t *testing.T) {
 	var expenseList expense.ExpenseList
 	
 	// Add some expenses.
-	if err := expenseList.Add("Demo Expense 1", 100); err != nil {
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 2", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
-	if err := expenseList.Add("Demo Expense 3", 150); err != nil {
+	if err := expenseList.Add("Demo Expense 3", 150_00, "", "", time.Time{}); err != nil {
 		t.Fatal(err)
 	}
 	
 	currentMonth := time.Now().Month()
-	expected := fmt.Sprintf("Total expenses for %s: $%.2f\n", currentMonth.String(), (100.0 + 150.0 + 150.0))
+	currentYear := time.Now().Year()
+	expected := fmt.Sprintf("Total expenses for %s %d: $%.2f\n", currentMonth.String(), currentYear, (100.0 + 150.0 + 150.0))
 	
 	var buf bytes.Buffer
-	expenseList.SummaryForMonth(&buf, int(currentMonth))
+	expenseList.SummaryForMonth(&buf, currentYear, int(currentMonth))
 	
 	if expected != buf.String() {
 		t.Errorf("expected %q, but got %q instead", expected, buf.String())
 	}
-}
\ No newline at end of file
+
+	// Assert the same month of the previous year is not included.
+	expected = fmt.Sprintf("Total expenses for %s %d: $%.2f\n", currentMonth.String(), currentYear-1, 0.0)
+
+	buf.Reset()
+	expenseList.SummaryForMonth(&buf, currentYear-1, int(currentMonth))
+
+	if expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+}
+func TestSummaryByCategory(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	// Add some expenses.
+	if err := expenseList.Add("Demo Expense 1", 100_00, "Food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 2", 150_00, "travel", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 3", 50_00, "food", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Demo Expense 4", 25_00, "", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Category", "Total"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "food", 150.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "travel", 150.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "uncategorized", 25.0))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "Total", 325.0))
+
+	var gotBuf bytes.Buffer
+	expenseList.SummaryByCategory(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := `[
+	{"id": 1, "date": "2024-12-31T23:59:59Z", "description": "party", "amount": 100},
+	{"id": 2, "date": "2025-01-01T00:00:00Z", "description": "brunch", "amount": 20},
+	{"id": 3, "date": "2025-03-31T12:00:00Z", "description": "taxes", "amount": 500},
+	{"id": 4, "date": "2025-04-01T00:00:00Z", "description": "flowers", "amount": 15}
+]`
+	if _, err := tempFile.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+
+	var expenseList expense.ExpenseList
+	if err := expenseList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		from, to time.Time
+		expected []int
+	}{
+		{name: "Quarter", from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), expected: []int{2, 3}},
+		{name: "From", from: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), expected: []int{3, 4}},
+		{name: "To", to: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), expected: []int{1}},
+		{name: "All", expected: []int{1, 2, 3, 4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []int
+			for _, item := range expenseList.Between(tc.from, tc.to) {
+				got = append(got, item.ID)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected IDs %v, but got %v instead", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummaryByMonth(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := `[
+	{"id": 1, "date": "2024-08-06T12:00:00Z", "description": "lunch", "amount": 10},
+	{"id": 2, "date": "2025-08-06T12:00:00Z", "description": "lunch", "amount": 20},
+	{"id": 3, "date": "2025-08-20T12:00:00Z", "description": "dinner", "amount": 30.5},
+	{"id": 4, "date": "2025-12-24T12:00:00Z", "description": "gifts", "amount": 100}
+]`
+	if _, err := tempFile.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+
+	var expenseList expense.ExpenseList
+	if err := expenseList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	totals := map[time.Month]float64{time.August: 50.5, time.December: 100}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Month", "Total"))
+	for month := time.January; month <= time.December; month++ {
+		expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", month, totals[month]))
+	}
+	expectedBuf.WriteString(fmt.Sprintf("%-20s$%.2f\n", "Total 2025", 150.5))
+
+	var gotBuf bytes.Buffer
+	expenseList.SummaryByMonth(&gotBuf, 2025)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+
+	expected := "Total expenses for 2024: $10.00\n"
+
+	var buf bytes.Buffer
+	expenseList.SummaryForYear(&buf, 2024)
+
+	if expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+}
+
+func TestExpenseDate(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	// Add an expense incurred in the past.
+	incurred := time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Old Receipt", 42_00, "", "", incurred); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("%-6d%-14s%-70s%-20s$%.2f", 1, "2024-03-15", "old receipt", "uncategorized", 42.0)
+	if expenseList[0].String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, expenseList[0].String())
+	}
+
+	// Assert that editing the receipt keeps the date it was incurred on.
+	if err := expenseList.Update(1, "Old Receipt Fixed", 24_00, "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if !expenseList[0].Date.Equal(incurred) {
+		t.Errorf("expected date %s, but got %s instead", incurred, expenseList[0].Date)
+	}
+	if !expenseList[0].UpdatedAt.After(expenseList[0].Date) || expenseList[0].CreatedAt.IsZero() {
+		t.Errorf("expected creation and modification times to be recorded, but got %s and %s instead",
+			expenseList[0].CreatedAt, expenseList[0].UpdatedAt)
+	}
+
+	// Assert that the date can be corrected explicitly.
+	corrected := time.Date(2024, 3, 16, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Update(1, "", -1, "", corrected); err != nil {
+		t.Fatal(err)
+	}
+	if !expenseList[0].Date.Equal(corrected) {
+		t.Errorf("expected date %s, but got %s instead", corrected, expenseList[0].Date)
+	}
+}
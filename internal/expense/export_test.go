@@ -0,0 +1,14 @@
+package expense
+
+import (
+	"io"
+	"os"
+)
+
+// SetFileWriter replaces the writer files are saved through and returns a
+// function that restores the original one.
+func SetFileWriter(writer func(f *os.File) io.Writer) (restore func()) {
+	original := fileWriter
+	fileWriter = writer
+	return func() { fileWriter = original }
+}
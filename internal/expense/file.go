@@ -0,0 +1,85 @@
+package expense
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// backupSuffix is appended to the name of a file to name the backup of its previous version.
+const backupSuffix = ".bak"
+
+// fileWriter returns the writer the contents of a temporary file are written
+// through. It is a variable so tests can simulate writes that fail partway.
+var fileWriter = func(f *os.File) io.Writer { return f }
+
+// writeFileAtomic writes data to the named file so that the file always holds
+// either its previous or its new contents, even if the process is killed or
+// the disk fills up mid-write. The data is written to a temporary file in the
+// same directory, flushed to disk and renamed over the original. The previous
+// version of the file, if any, is kept next to it with the ".bak" suffix.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(filename)
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	// Remove the temporary file unless it was renamed over the original.
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err = fileWriter(temp).Write(data); err != nil {
+		return err
+	}
+	if err = temp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+
+	if err = backupFile(filename); err != nil {
+		return err
+	}
+	if err = os.Rename(temp.Name(), filename); err != nil {
+		return err
+	}
+
+	// Flush the rename itself to disk. Not every platform supports syncing a
+	// directory, so a failure here is not reported.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// backupFile replaces the backup of the named file with its current contents.
+// A missing file is not an error.
+func backupFile(filename string) error {
+	backup := filename + backupSuffix
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// Hard link the current version where possible and fall back to a copy.
+	err := os.Link(filename, backup)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(backup, content, 0644)
+}
@@ -0,0 +1,114 @@
+package expense_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+// failingWriter writes up to limit bytes and then fails as if the disk was full.
+type failingWriter struct {
+	w     io.Writer
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n, _ := f.w.Write(p[:f.limit])
+		f.limit -= n
+		return n, syscall.ENOSPC
+	}
+	n, err := f.w.Write(p)
+	f.limit -= n
+	return n, err
+}
+
+func TestSaveFailsPartway(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "expenses.json")
+
+	var expenseList expense.ExpenseList
+	if err := expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail the next save halfway through writing the file.
+	if err := expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	restore := expense.SetFileWriter(func(f *os.File) io.Writer { return &failingWriter{w: f, limit: 40} })
+	err := expenseList.Save(filename)
+	restore()
+	if err == nil {
+		t.Fatal("expected the save to fail, but got nil instead")
+	}
+
+	// Assert the ledger still holds the previous version.
+	var loaded expense.ExpenseList
+	if err := loaded.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 {
+		t.Errorf("expected length of the expense list: %d, but got %d instead", 1, len(loaded))
+	}
+
+	// Assert no temporary file was left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the ledger in the directory, but got %d entries instead", len(entries))
+	}
+
+	// Assert a successful save keeps the previous version as a backup.
+	if err := expenseList.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	var backup expense.ExpenseList
+	if err := backup.Load(filename + ".bak"); err != nil {
+		t.Fatal(err)
+	}
+	if len(backup) != 1 {
+		t.Errorf("expected length of the backup: %d, but got %d instead", 1, len(backup))
+	}
+
+	loaded = nil
+	if err := loaded.Load(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 {
+		t.Errorf("expected length of the expense list: %d, but got %d instead", 2, len(loaded))
+	}
+}
+
+func TestSaveKeepsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+
+	filename := filepath.Join(t.TempDir(), "expenses.json")
+
+	var expenseList expense.ExpenseList
+	if err := expenseList.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("expected permissions %v, but got %v instead", os.FileMode(0644), info.Mode().Perm())
+	}
+}
@@ -0,0 +1,441 @@
+package expense
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Actions recorded by the changes of a HistoryList. An expense is deleted
+// when it is moved to the trash and purged when it is removed for good.
+const (
+	actionAdd     = "add"
+	actionUpdate  = "update"
+	actionDelete  = "delete"
+	actionRestore = "restore"
+	actionPurge   = "purge"
+)
+
+// change records one change made to an expense, with the expense as it was
+// before and after the change. The changes made together, such as by a single
+// command, share a group and are undone and redone together.
+type change struct {
+	Seq    int       `json:"-"`                // Position of the change in the history, from 1
+	Group  int       `json:"group,omitempty"`  // Group of the change, increasing through the history
+	Time   time.Time `json:"time"`             // When the change was made
+	Action string    `json:"action"`           // One of the actions, such as actionAdd
+	Before *expense  `json:"before,omitempty"` // The expense before the change, nil if it was added
+	After  *expense  `json:"after,omitempty"`  // The expense after the change, nil if it was deleted
+	Undone bool      `json:"-"`                // Whether the change was undone
+
+	// Whether the undone change can no longer be redone, because a new change
+	// was made after it was undone.
+	Discarded bool `json:"-"`
+}
+
+// historyEvent is a line of the history file: a change that was made, or the
+// undo or redo of the change with the given position in the history.
+type historyEvent struct {
+	Change *change   `json:"change,omitempty"` // The change that was made
+	Undo   int       `json:"undo,omitempty"`   // Position of the change that was undone
+	Redo   int       `json:"redo,omitempty"`   // Position of the change that was redone
+	Time   time.Time `json:"time,omitempty"`   // When the change was undone or redone
+}
+
+// expenseID returns the ID of the changed expense.
+func (c change) expenseID() int {
+	if c.After != nil {
+		return c.After.ID
+	}
+	return c.Before.ID
+}
+
+// String returns a short description of the change, such as "update of expense 3".
+func (c change) String() string {
+	return fmt.Sprintf("%s of expense %d", c.Action, c.expenseID())
+}
+
+// details describes what the change did: the expense that was added, deleted,
+// restored or purged, or the fields that were updated with their old and new values.
+func (c change) details() string {
+	if c.After == nil {
+		return describe(*c.Before)
+	}
+	if c.Action != actionUpdate {
+		return describe(*c.After)
+	}
+
+	before, after := *c.Before, *c.After
+	var fields []string
+	if !before.Date.Equal(after.Date) {
+		fields = append(fields, fmt.Sprintf("date: %s -> %s", before.Date.Format("2006-01-02"), after.Date.Format("2006-01-02")))
+	}
+	if before.Description != after.Description {
+		fields = append(fields, fmt.Sprintf("description: %q -> %q", before.Description, after.Description))
+	}
+	if before.Category != after.Category {
+		fields = append(fields, fmt.Sprintf("category: %s -> %s", before.categoryName(), after.categoryName()))
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		fields = append(fields, fmt.Sprintf("tags: %s -> %s", strings.Join(before.Tags, ","), strings.Join(after.Tags, ",")))
+	}
+	if before.Account != after.Account {
+		fields = append(fields, fmt.Sprintf("account: %s -> %s", before.accountName(), after.accountName()))
+	}
+	if !slices.Equal(before.Parts, after.Parts) {
+		fields = append(fields, fmt.Sprintf("parts: %s -> %s", formatParts(before.Parts), formatParts(after.Parts)))
+	}
+	if before.Amount != after.Amount || before.currencyCode() != after.currencyCode() {
+		fields = append(fields, fmt.Sprintf("amount: %s -> %s",
+			formatAmount(before.Amount, before.currencyCode()), formatAmount(after.Amount, after.currencyCode())))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// describe returns the description, amount and date of the expense.
+func describe(item expense) string {
+	return fmt.Sprintf("%q %s on %s", item.Description, item.formattedAmount(), item.Date.Format("2006-01-02"))
+}
+
+// HistoryList represents the history of the changes made to the expenses of a ledger.
+type HistoryList []change
+
+// Load reads the history from the specified file and loads it into the HistoryList.
+// The file holds JSON lines that are only ever appended to: the changes in the
+// order they were made, and the undo and redo of each. It returns an error if
+// there is any issue reading or parsing the file.
+func (h *HistoryList) Load(filename string) error {
+	*h = HistoryList{}
+	err := readLines(filename, func(ev historyEvent) error {
+		switch {
+		case ev.Change != nil:
+			h.record(*ev.Change)
+		case ev.Undo > 0 && ev.Undo <= len(*h):
+			(*h)[ev.Undo-1].Undone = true
+		case ev.Redo > 0 && ev.Redo <= len(*h):
+			(*h)[ev.Redo-1].Undone = false
+		default:
+			return errors.New("event without a known change")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid history %s: %w", filename, err)
+	}
+	return nil
+}
+
+// record appends the change to the HistoryList, numbering it. A change
+// recorded without a group, as the history used to be, is a group of its own.
+// Changes that were undone can no longer be redone once a new change is recorded.
+func (h *HistoryList) record(c change) {
+	for index := range *h {
+		if (*h)[index].Undone {
+			(*h)[index].Discarded = true
+		}
+	}
+	c.Seq = len(*h) + 1
+	if c.Group == 0 {
+		c.Group = h.lastGroup() + 1
+	}
+	*h = append(*h, c)
+}
+
+// lastGroup returns the group of the most recent change, or zero if there is none.
+func (h *HistoryList) lastGroup() int {
+	if len(*h) == 0 {
+		return 0
+	}
+	return (*h)[len(*h)-1].Group
+}
+
+// String returns a short description of the changes, such as
+// "update of expense 3, update of expense 4".
+func (h HistoryList) String() string {
+	descriptions := make([]string, 0, len(h))
+	for _, item := range h {
+		descriptions = append(descriptions, item.String())
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// List writes the changes to the provided io.Writer in a tabular format, most
+// recent last. If id is not zero, only the changes to the expense with that ID
+// are written.
+func (h *HistoryList) List(w io.Writer, id int) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-8s%-18s%-6s%-16s%s\n", "Change", "Time", "ID", "Action", "Details"))
+
+	for _, item := range *h {
+		if id != 0 && item.expenseID() != id {
+			continue
+		}
+
+		action := item.Action
+		if item.Undone {
+			action += " (undone)"
+		}
+		buf.WriteString(fmt.Sprintf("%-8d%-18s%-6d%-16s%s\n", item.Seq, item.Time.Format("2006-01-02 15:04"),
+			item.expenseID(), action, item.details()))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// HistoryStore is a Store that records every change made through it in a
+// HistoryList kept in a file, so that changes can be reviewed and undone. Each
+// change, undo and redo appends a line to the file instead of rewriting it.
+type HistoryStore struct {
+	Store
+	filename string
+	current  ExpenseList // The expenses in the Store, to look up their values before a change
+	group    int         // Group of the changes recorded since Begin, zero until the first one
+	grouped  bool        // Whether Begin was called
+}
+
+// NewHistoryStore returns a HistoryStore that records the changes made to
+// the store in the history kept in the named file.
+func NewHistoryStore(store Store, filename string) *HistoryStore {
+	return &HistoryStore{Store: store, filename: filename}
+}
+
+// Begin starts a group of changes: the changes recorded until Begin is called
+// again, such as every expense changed by a command, are undone and redone
+// together. Until Begin is called, every write to the Store is a group of its own.
+func (s *HistoryStore) Begin() {
+	s.group, s.grouped = 0, true
+}
+
+// Load returns every expense in the Store.
+func (s *HistoryStore) Load() (ExpenseList, error) {
+	e, err := s.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	s.current = slices.Clone(e)
+	return e, nil
+}
+
+// Append adds the expenses to the Store and records their addition.
+func (s *HistoryStore) Append(items ExpenseList) error {
+	if err := s.Store.Append(items); err != nil {
+		return err
+	}
+
+	changes := make([]change, 0, len(items))
+	for _, item := range items {
+		s.current = append(s.current, item)
+		changes = append(changes, change{Action: actionAdd, After: &item})
+	}
+	return s.record(changes...)
+}
+
+// Update replaces the expense in the Store that has the ID of the given one
+// and records its values before and after. Moving the expense to the trash is
+// recorded as a deletion and moving it out as a restore.
+func (s *HistoryStore) Update(item expense) error {
+	before, err := s.get(item.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Update(item); err != nil {
+		return err
+	}
+
+	action := actionUpdate
+	switch {
+	case !before.deleted() && item.deleted():
+		action = actionDelete
+	case before.deleted() && !item.deleted():
+		action = actionRestore
+	}
+
+	s.set(item)
+	return s.record(change{Action: action, Before: &before, After: &item})
+}
+
+// Delete removes the expense with the specified ID from the Store for good and
+// records its values before the removal as a purge.
+func (s *HistoryStore) Delete(id int) error {
+	before, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Delete(id); err != nil {
+		return err
+	}
+
+	s.current = s.current.filter(func(item expense) bool { return item.ID != id })
+	return s.record(change{Action: actionPurge, Before: &before})
+}
+
+// History returns the recorded changes.
+func (s *HistoryStore) History() (HistoryList, error) {
+	var h HistoryList
+	if err := h.Load(s.filename); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Renumber gives the expenses just added to a list, before they are stored,
+// IDs above any an expense has had in the history, so that the ID of an expense
+// removed for good, or whose addition was undone, is never used again. Refunds
+// among the expenses stay linked to their expense. The expenses are changed in
+// place, so the end of the list they were added to is passed.
+func (s *HistoryStore) Renumber(items ExpenseList) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	h, err := s.History()
+	if err != nil {
+		return err
+	}
+	last := 0
+	for _, item := range h {
+		last = max(last, item.expenseID())
+	}
+
+	first := items[0].ID
+	shift := last + 1 - first
+	if shift <= 0 {
+		return nil
+	}
+	for index := range items {
+		items[index].ID += shift
+		if items[index].RefundOf >= first {
+			items[index].RefundOf += shift
+		}
+	}
+	return nil
+}
+
+// Undo reverts the most recent group of changes that was not undone yet, most
+// recent change first, and returns the changes in the order they were reverted.
+// It returns an error if there is no change to undo.
+func (s *HistoryStore) Undo() (HistoryList, error) {
+	h, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+
+	index := len(h) - 1
+	for index >= 0 && h[index].Undone {
+		index--
+	}
+	if index < 0 {
+		return nil, errors.New("nothing to undo")
+	}
+
+	var undone HistoryList
+	var events []historyEvent
+	for group := h[index].Group; index >= 0 && h[index].Group == group; index-- {
+		if h[index].Undone {
+			continue
+		}
+		if err := s.apply(h[index].After, h[index].Before); err != nil {
+			return nil, errors.Join(err, appendLines(s.filename, events...))
+		}
+		h[index].Undone = true
+		undone = append(undone, h[index])
+		events = append(events, historyEvent{Undo: h[index].Seq, Time: time.Now()})
+	}
+	return undone, appendLines(s.filename, events...)
+}
+
+// Redo makes the most recently undone group of changes again, in the order
+// they were first made, and returns them. Only the changes undone since the
+// last new change can be redone. It returns an error if there is no change to redo.
+func (s *HistoryStore) Redo() (HistoryList, error) {
+	h, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+
+	// The changes that can be redone are the undone ones at the end of the
+	// history, and they are redone in the order they were first made.
+	index := len(h)
+	for index > 0 && h[index-1].Undone && !h[index-1].Discarded {
+		index--
+	}
+	if index == len(h) {
+		return nil, errors.New("nothing to redo")
+	}
+
+	var redone HistoryList
+	var events []historyEvent
+	for group := h[index].Group; index < len(h) && h[index].Group == group; index++ {
+		if err := s.apply(h[index].Before, h[index].After); err != nil {
+			return nil, errors.Join(err, appendLines(s.filename, events...))
+		}
+		h[index].Undone = false
+		redone = append(redone, h[index])
+		events = append(events, historyEvent{Redo: h[index].Seq, Time: time.Now()})
+	}
+	return redone, appendLines(s.filename, events...)
+}
+
+// apply changes the expense in the Store from one value to another without
+// recording the change: it adds the expense if from is nil, deletes it if to
+// is nil and updates it otherwise.
+func (s *HistoryStore) apply(from, to *expense) error {
+	// Look the expenses up again the next time they are needed.
+	s.current = nil
+
+	switch {
+	case from == nil:
+		return s.Store.Append(ExpenseList{*to})
+	case to == nil:
+		return s.Store.Delete(from.ID)
+	default:
+		return s.Store.Update(*to)
+	}
+}
+
+// record appends the changes to the history, stamped with the current time.
+// They join the group started by Begin, or make up a new group of their own.
+func (s *HistoryStore) record(changes ...change) error {
+	if !s.grouped || s.group == 0 {
+		h, err := s.History()
+		if err != nil {
+			return err
+		}
+		s.group = h.lastGroup() + 1
+	}
+
+	now := time.Now()
+	events := make([]historyEvent, 0, len(changes))
+	for _, c := range changes {
+		c.Time, c.Group = now, s.group
+		events = append(events, historyEvent{Change: &c})
+	}
+	return appendLines(s.filename, events...)
+}
+
+// get returns the expense with the specified ID as it is in the Store, in the
+// trash or not.
+func (s *HistoryStore) get(id int) (expense, error) {
+	if s.current == nil {
+		if _, err := s.Load(); err != nil {
+			return expense{}, err
+		}
+	}
+
+	index, err := s.current.indexOf(id)
+	if err != nil {
+		return expense{}, err
+	}
+	return s.current[index], nil
+}
+
+// set replaces the expense with the ID of the given one in the current expenses.
+func (s *HistoryStore) set(item expense) {
+	if index, err := s.current.indexOf(item.ID); err == nil {
+		s.current[index] = item
+	}
+}
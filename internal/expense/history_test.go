@@ -0,0 +1,168 @@
+package expense_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "history.jsonl")
+	store := expense.NewHistoryStore(expense.NewFileStore(filepath.Join(dir, "expenses.json")), filename)
+
+	e, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	e.Add("lunch", 20_00, "food", "", date)
+	e.Add("taxi", 15_00, "travel", "", date)
+	if err := store.Append(e); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Update(1, "", 25_00, "", time.Time{})
+	item, _ := e.Get(1)
+	if err := store.Update(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := store.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("expected %d changes, but got %d instead", 4, len(history))
+	}
+
+	var buf bytes.Buffer
+	history.List(&buf, 1)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and %d changes, but got %q instead", 2, buf.String())
+	}
+	if !strings.HasSuffix(lines[2], "amount: $20.00 -> $25.00") {
+		t.Errorf("expected the updated amount, but got %q instead", lines[2])
+	}
+
+	recorded, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert undo reverts the changes most recent first.
+	undone, err := store.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undone.String() != "purge of expense 2" {
+		t.Errorf("expected %q, but got %q instead", "purge of expense 2", undone.String())
+	}
+	if _, err := store.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("expected %d expenses, but got %d instead", 2, len(loaded))
+	}
+	if item, _ := loaded.Get(1); item.Amount != 20_00 {
+		t.Errorf("expected the amount %s, but got %s instead", expense.Money(20_00), item.Amount)
+	}
+
+	// Assert redo makes the undone changes again in their original order.
+	redone, err := store.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redone.String() != "update of expense 1" {
+		t.Errorf("expected %q, but got %q instead", "update of expense 1", redone.String())
+	}
+
+	// Assert a new change discards the changes left to redo.
+	if err := store.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Redo(); err == nil {
+		t.Error("expected nothing to redo after a new change")
+	}
+
+	// Assert the expenses added together are removed together.
+	for range 2 {
+		if _, err := store.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	undone, err = store.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undone.String() != "add of expense 2, add of expense 1" {
+		t.Errorf("expected %q, but got %q instead", "add of expense 2, add of expense 1", undone.String())
+	}
+	if _, err := store.Undo(); err == nil {
+		t.Error("expected nothing to undo")
+	}
+	if loaded, _ = store.Load(); len(loaded) != 0 {
+		t.Errorf("expected no expenses, but got %v instead", loaded)
+	}
+
+	// Assert the history was only ever appended to.
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(content, recorded) {
+		t.Errorf("expected the history to start with %q, but got %q instead", recorded, content)
+	}
+	if history, _ = store.History(); len(history) != 5 || !history[4].Undone {
+		t.Errorf("expected 5 changes, the last one undone, but got %v instead", history)
+	}
+}
+
+func TestHistoryStoreRenumber(t *testing.T) {
+	dir := t.TempDir()
+	store := expense.NewHistoryStore(expense.NewFileStore(filepath.Join(dir, "expenses.json")), filepath.Join(dir, "history.jsonl"))
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("hotel", 150_00, "travel", "", time.Time{})
+	if err := store.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the expenses added next, and the refund among them, skip the removed ID.
+	e, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := e.Refund(2, 5_00, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Renumber(e[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if e[1].ID != 3 || e[2].ID != 4 {
+		t.Errorf("expected the IDs %d and %d, but got %d and %d instead", 3, 4, e[1].ID, e[2].ID)
+	}
+	if refunds := e.Refunds(3); len(refunds) != 1 {
+		t.Errorf("expected the refund of expense %d, but got %v instead", 3, refunds)
+	}
+}
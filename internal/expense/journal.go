@@ -0,0 +1,298 @@
+package expense
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Operations recorded by the events of a journal.
+const (
+	opSnapshot = "snapshot"
+	opAdd      = "add"
+	opUpdate   = "update"
+	opDelete   = "delete"
+)
+
+// event is a line of a journal, recording one change to the expenses.
+type event struct {
+	Op       string      `json:"op"`                 // One of opSnapshot, opAdd, opUpdate or opDelete
+	Time     time.Time   `json:"time"`               // When the change was made
+	Expenses ExpenseList `json:"expenses,omitempty"` // Every expense, for a snapshot
+	Expense  *expense    `json:"expense,omitempty"`  // The added or updated expense
+	ID       int         `json:"id,omitempty"`       // ID of the deleted expense
+}
+
+// JournalStore is a Store that keeps the expenses in an append-only journal
+// of JSON lines. Every change appends one event to the journal instead of
+// rewriting it, and loading replays the events in order. Compact folds the
+// events into a single snapshot once the journal grows long.
+type JournalStore struct {
+	filename string
+	ids      map[int]bool // IDs of the expenses, known once the journal is replayed
+}
+
+// NewJournalStore returns the JournalStore that keeps the expenses in the named file.
+func NewJournalStore(filename string) *JournalStore {
+	return &JournalStore{filename: filename}
+}
+
+// Load replays the journal and returns the resulting expenses.
+func (s *JournalStore) Load() (ExpenseList, error) {
+	e, _, err := s.replay()
+	return e, err
+}
+
+// Append appends an add event to the journal for every expense.
+func (s *JournalStore) Append(items ExpenseList) error {
+	events := make([]event, 0, len(items))
+	for _, item := range items {
+		events = append(events, event{Op: opAdd, Expense: &item})
+	}
+	if err := s.write(events...); err != nil {
+		return err
+	}
+
+	if s.ids != nil {
+		for _, item := range items {
+			s.ids[item.ID] = true
+		}
+	}
+	return nil
+}
+
+// Update appends an update event for the expense to the journal. It returns a
+// *NotFoundError if no expense has the ID of the given one.
+func (s *JournalStore) Update(item expense) error {
+	if err := s.check(item.ID); err != nil {
+		return err
+	}
+	return s.write(event{Op: opUpdate, Expense: &item})
+}
+
+// Delete appends a delete event for the expense with the specified ID to the
+// journal. It returns a *NotFoundError if no expense has the ID.
+func (s *JournalStore) Delete(id int) error {
+	if err := s.check(id); err != nil {
+		return err
+	}
+	if err := s.write(event{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+
+	delete(s.ids, id)
+	return nil
+}
+
+// Query replays the journal and returns the expenses that match the query.
+func (s *JournalStore) Query(q Query) (ExpenseList, error) {
+	e, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return e.filter(q.matches), nil
+}
+
+// Close does nothing, as the journal is only open while it is read or written.
+func (s *JournalStore) Close() error {
+	return nil
+}
+
+// Compact replaces the journal with a single snapshot of the expenses and
+// returns the number of events folded into it. The journal is replaced
+// atomically, so a failure leaves it unchanged.
+func (s *JournalStore) Compact() (int, error) {
+	e, events, err := s.replay()
+	if err != nil {
+		return 0, err
+	}
+
+	line, err := json.Marshal(event{Op: opSnapshot, Time: time.Now(), Expenses: e})
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(s.filename, append(line, '\n'), 0644); err != nil {
+		return 0, err
+	}
+	return events, nil
+}
+
+// replay reads the journal and applies its events in order. It returns the
+// resulting expenses and the number of events read, and keeps their IDs for
+// check. A missing journal holds no expenses.
+func (s *JournalStore) replay() (ExpenseList, int, error) {
+	e := ExpenseList{}
+	events := 0
+
+	err := readLines(s.filename, func(ev event) error {
+		events++
+		return e.apply(ev)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid journal %s: %w", s.filename, err)
+	}
+
+	s.ids = make(map[int]bool, len(e))
+	for _, item := range e {
+		s.ids[item.ID] = true
+	}
+	return e, events, nil
+}
+
+// apply applies the change recorded by the event to the ExpenseList.
+func (e *ExpenseList) apply(ev event) error {
+	switch ev.Op {
+	case opSnapshot:
+		*e = slices.Clone(ev.Expenses)
+	case opAdd:
+		if ev.Expense == nil {
+			return errors.New("add event without an expense")
+		}
+		*e = append(*e, *ev.Expense)
+	case opUpdate:
+		if ev.Expense == nil {
+			return errors.New("update event without an expense")
+		}
+		index, err := e.indexOf(ev.Expense.ID)
+		if err != nil {
+			return err
+		}
+		(*e)[index] = *ev.Expense
+	case opDelete:
+		index, err := e.indexOf(ev.ID)
+		if err != nil {
+			return err
+		}
+		*e = slices.Delete(*e, index, index+1)
+	default:
+		return fmt.Errorf("unknown operation %q", ev.Op)
+	}
+	return nil
+}
+
+// check returns a *NotFoundError if the journal holds no expense with the ID.
+// It looks the ID up in the IDs known from the last replay, so that a change
+// does not replay the whole journal again, and only replays the journal if it
+// was not replayed yet.
+func (s *JournalStore) check(id int) error {
+	if s.ids == nil {
+		if _, _, err := s.replay(); err != nil {
+			return err
+		}
+	}
+	if !s.ids[id] {
+		return &NotFoundError{ID: id}
+	}
+	return nil
+}
+
+// write appends the events to the journal, stamped with the current time.
+func (s *JournalStore) write(events ...event) error {
+	now := time.Now()
+	for index := range events {
+		events[index].Time = now
+	}
+	return appendLines(s.filename, events...)
+}
+
+// appendLines appends the values to the named file as JSON lines, in a single
+// write that is flushed to disk before it returns.
+func appendLines[T any](filename string, values ...T) error {
+	var buf bytes.Buffer
+	for _, value := range values {
+		line, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	end, err := completeEnd(file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteAt(buf.Bytes(), end); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readLines reads the named file of JSON lines and calls read with every value
+// in order, skipping blank lines. A missing file holds no values. A last line
+// that is cut short, as left by a crash mid-write, is ignored, but any other
+// line that cannot be read is an error naming the line.
+func readLines[T any](filename string, read func(value T) error) error {
+	content, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	lines := bytes.Split(content, []byte("\n"))
+	for number, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var value T
+		if err := json.Unmarshal(line, &value); err != nil {
+			if number == len(lines)-1 {
+				break
+			}
+			return fmt.Errorf("line %d: %w", number+1, err)
+		}
+		if err := read(value); err != nil {
+			return fmt.Errorf("line %d: %w", number+1, err)
+		}
+	}
+	return nil
+}
+
+// completeEnd returns the offset just past the last complete line of the
+// journal, so that the next event does not run into the last line. A last line
+// that only lacks its newline is kept and given one, as readLines reads it,
+// while a last line cut short by a crash is truncated.
+func completeEnd(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return 0, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, size-1); err != nil {
+		return 0, err
+	}
+	if last[0] == '\n' {
+		return size, nil
+	}
+
+	content := make([]byte, size)
+	if _, err := file.ReadAt(content, 0); err != nil {
+		return 0, err
+	}
+	end := int64(bytes.LastIndexByte(content, '\n') + 1)
+	if json.Valid(content[end:]) {
+		if _, err := file.WriteAt([]byte("\n"), size); err != nil {
+			return 0, err
+		}
+		return size + 1, nil
+	}
+	return end, file.Truncate(end)
+}
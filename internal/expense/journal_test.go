@@ -0,0 +1,172 @@
+package expense_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestJournalStore(t *testing.T) {
+	store := expense.NewJournalStore(filepath.Join(t.TempDir(), "expenses.jsonl"))
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestJournalCompact(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.jsonl")
+	store := expense.NewJournalStore(filename)
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := store.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events != 3 {
+		t.Errorf("expected %d events, but got %d instead", 3, events)
+	}
+
+	// Assert the journal was folded into a single line.
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(content, []byte("\n")); lines != 1 {
+		t.Errorf("expected %d line, but got %d instead", 1, lines)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].ID != 2 {
+		t.Errorf("expected the remaining expense, but got %v instead", loaded)
+	}
+
+	// Assert new events are replayed on top of the snapshot.
+	if err := store.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ = store.Load(); len(loaded) != 0 {
+		t.Errorf("expected no expenses, but got %v instead", loaded)
+	}
+}
+
+func TestJournalTornLine(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.jsonl")
+	store := expense.NewJournalStore(filename)
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := store.Append(e[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash partway through writing an event.
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"op":"add","expense":{"id":2,"desc`)
+	file.Close()
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 {
+		t.Errorf("expected %d expense, but got %d instead", 1, len(loaded))
+	}
+
+	// Assert the next event is not corrupted by the torn line.
+	if err := store.Append(e[1:]); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[1].Description != "taxi" {
+		t.Errorf("expected both expenses, but got %v instead", loaded)
+	}
+}
+
+func TestJournalLineWithoutNewline(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.jsonl")
+	store := expense.NewJournalStore(filename)
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := store.Append(e[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a journal whose complete last line lacks its newline.
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, bytes.TrimSuffix(content, []byte("\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the last line is kept when the next event is appended.
+	if err := store.Append(e[1:]); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].Description != "lunch" || loaded[1].Description != "taxi" {
+		t.Errorf("expected both expenses, but got %v instead", loaded)
+	}
+}
+
+func TestJournalChangeWithoutReplay(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.jsonl")
+	store := expense.NewJournalStore(filename)
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := store.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A line that cannot be read shows whether a change replays the journal.
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("not an event\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	item, _ := e.Get(2)
+	if err := store.Update(item); err != nil {
+		t.Errorf("expected the update to skip replaying the journal, but got %v instead", err)
+	}
+	if err := store.Delete(1); err != nil {
+		t.Errorf("expected the delete to skip replaying the journal, but got %v instead", err)
+	}
+}
@@ -0,0 +1,237 @@
+package expense
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DefaultLedger is the name of the ledger used until another one is selected.
+const DefaultLedger = "default"
+
+// appName names the directory the named ledgers are kept in.
+const appName = "expense-tracker"
+
+// currentFilename is the file in the data directory that holds the name of the selected ledger.
+const currentFilename = "current"
+
+// ledgerFilenames are the names of the expense file in the directory of a named
+// ledger, in order of preference: the SQLite database or journal the ledger was
+// migrated into, or else its JSON file.
+var ledgerFilenames = []string{"expense_list.db", "expense_list.jsonl", "expense_list.journal", "expense_list.json"}
+
+// Ledger holds the paths of the files that make up a ledger: the expenses and
+// the budgets, exchange rates, recurring expense rules, history of changes and
+// payment accounts kept alongside them.
+type Ledger struct {
+	Expenses  string // File of the ExpenseList
+	Budgets   string // File of the BudgetList
+	Rates     string // File of the RateList
+	Recurring string // File of the RecurringList
+	History   string // File of the HistoryList
+	Accounts  string // File of the AccountList
+}
+
+// NewLedger returns the Ledger whose expenses are kept in the named file. The
+// other files are kept in the same directory and named after it, such that
+// "personal.json" is accompanied by "personal_budget.json", "personal_rates.json",
+// "personal_recurring.json", "personal_history.jsonl" and "personal_accounts.json".
+// A "_list" suffix is dropped first, so that ".expense_list.json" is accompanied
+// by ".expense_budget.json" and so on.
+func NewLedger(filename string) Ledger {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	base = strings.TrimSuffix(base, "_list")
+
+	return Ledger{
+		Expenses:  filename,
+		Budgets:   base + "_budget.json",
+		Rates:     base + "_rates.json",
+		Recurring: base + "_recurring.json",
+		History:   base + "_history.jsonl",
+		Accounts:  base + "_accounts.json",
+	}
+}
+
+// DataDir returns the directory the named ledgers are kept in. It follows the
+// XDG base directory specification: $XDG_DATA_HOME/expense-tracker, or
+// ~/.local/share/expense-tracker if XDG_DATA_HOME is not set. On Windows the
+// directory is kept in %AppData% instead.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+// LedgerPath returns the path of the expense file of the named ledger, which is
+// kept in a directory of its own in the data directory. The SQLite database or
+// journal of a migrated ledger is preferred over its JSON file. It returns an
+// error if the name is not made up of letters, digits, dashes and underscores.
+func LedgerPath(name string) (string, error) {
+	paths, err := LedgerPaths(name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range paths[:len(paths)-1] {
+		if exists(path) {
+			return path, nil
+		}
+	}
+	return paths[len(paths)-1], nil
+}
+
+// LedgerPaths returns every path the expense file of the named ledger may
+// have, in the order LedgerPath prefers them: "expense_list.db",
+// "expense_list.jsonl", "expense_list.journal" and "expense_list.json" in the
+// directory of the ledger. It returns an error if the name is invalid.
+func LedgerPaths(name string) ([]string, error) {
+	if err := validateLedgerName(name); err != nil {
+		return nil, err
+	}
+
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(ledgerFilenames))
+	for _, filename := range ledgerFilenames {
+		paths = append(paths, filepath.Join(dir, name, filename))
+	}
+	return paths, nil
+}
+
+// CurrentLedger returns the name of the selected ledger, or DefaultLedger if
+// no ledger was selected.
+func CurrentLedger() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, currentFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultLedger, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(string(content))
+	if name == "" {
+		return DefaultLedger, nil
+	}
+	return name, validateLedgerName(name)
+}
+
+// CreateLedger creates the named ledger with no expenses. It returns an error
+// if the name is invalid or the ledger already exists.
+func CreateLedger(name string) error {
+	path, err := LedgerPath(name)
+	if err != nil {
+		return err
+	}
+
+	if exists(path) {
+		return fmt.Errorf("ledger already exists: %q", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	var e ExpenseList
+	return e.Save(path)
+}
+
+// SwitchLedger selects the named ledger for the commands that follow. It
+// returns an *InputError if the name is invalid, or a *NotFoundError if no
+// such ledger exists, unless it is the DefaultLedger, which always exists.
+func SwitchLedger(name string) error {
+	path, err := LedgerPath(name)
+	if err != nil {
+		return err
+	}
+
+	if !exists(path) && name != DefaultLedger {
+		return notFoundf("ledger not found: no ledger named %q", name)
+	}
+
+	dir, err := DataDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, currentFilename), []byte(name+"\n"), 0644)
+}
+
+// Ledgers returns the sorted names of the ledgers in the data directory,
+// including the DefaultLedger.
+func Ledgers() ([]string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	names := []string{DefaultLedger}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == DefaultLedger || validateLedgerName(name) != nil {
+			continue
+		}
+		if slices.ContainsFunc(ledgerFilenames, func(filename string) bool { return exists(filepath.Join(dir, name, filename)) }) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// validateLedgerName checks that the ledger name is made up of letters, digits,
+// dashes and underscores.
+func validateLedgerName(name string) error {
+	if !isName(name) {
+		return inputErrorf("invalid ledger name %q: expected letters, digits, dashes and underscores", name)
+	}
+	return nil
+}
+
+// isName reports whether the name is made up of letters, digits, dashes and
+// underscores, as the names of ledgers and accounts are.
+func isName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) < 0
+}
+
+// exists reports whether the named file exists.
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
@@ -0,0 +1,108 @@
+package expense_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestNewLedger(t *testing.T) {
+	testCases := []struct {
+		filename string
+		expected expense.Ledger
+	}{
+		{
+			filename: ".expense_list.json",
+			expected: expense.Ledger{
+				Expenses:  ".expense_list.json",
+				Budgets:   ".expense_budget.json",
+				Rates:     ".expense_rates.json",
+				Recurring: ".expense_recurring.json",
+				History:   ".expense_history.jsonl",
+				Accounts:  ".expense_accounts.json",
+			},
+		},
+		{
+			filename: filepath.Join("data", "personal.json"),
+			expected: expense.Ledger{
+				Expenses:  filepath.Join("data", "personal.json"),
+				Budgets:   filepath.Join("data", "personal_budget.json"),
+				Rates:     filepath.Join("data", "personal_rates.json"),
+				Recurring: filepath.Join("data", "personal_recurring.json"),
+				History:   filepath.Join("data", "personal_history.jsonl"),
+				Accounts:  filepath.Join("data", "personal_accounts.json"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if ledger := expense.NewLedger(tc.filename); ledger != tc.expected {
+			t.Errorf("expected %+v, but got %+v instead", tc.expected, ledger)
+		}
+	}
+}
+
+func TestLedgers(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	current, err := expense.CurrentLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != expense.DefaultLedger {
+		t.Errorf("expected the %q ledger, but got %q instead", expense.DefaultLedger, current)
+	}
+
+	if err := expense.SwitchLedger("personal"); err == nil {
+		t.Error("expected an error switching to a missing ledger")
+	}
+
+	for _, name := range []string{"team-offsite", "personal"} {
+		if err := expense.CreateLedger(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := expense.CreateLedger("personal"); err == nil {
+		t.Error("expected an error creating an existing ledger")
+	}
+	if err := expense.CreateLedger("../escape"); err == nil {
+		t.Error("expected an error creating a ledger with an invalid name")
+	}
+
+	if err := expense.SwitchLedger("personal"); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ = expense.CurrentLedger(); current != "personal" {
+		t.Errorf("expected the %q ledger, but got %q instead", "personal", current)
+	}
+
+	path, err := expense.LedgerPath("personal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "expense-tracker", "personal", "expense_list.json"); path != expected {
+		t.Errorf("expected %q, but got %q instead", expected, path)
+	}
+
+	// Assert the journal of a migrated ledger is preferred over its JSON file.
+	journal := filepath.Join(dir, "expense-tracker", "personal", "expense_list.jsonl")
+	if err := os.WriteFile(journal, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err = expense.LedgerPath("personal"); err != nil || path != journal {
+		t.Errorf("expected %q, but got %q (%v) instead", journal, path, err)
+	}
+
+	names, err := expense.Ledgers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"default", "personal", "team-offsite"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected %q, but got %q instead", expected, names)
+	}
+}
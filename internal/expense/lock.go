@@ -0,0 +1,88 @@
+package expense
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockSuffix is appended to the name of a file to name its lock file.
+const lockSuffix = ".lock"
+
+// lockRetryInterval is how long AcquireLock waits between attempts to take a held lock.
+const lockRetryInterval = 50 * time.Millisecond
+
+// errLocked is returned by tryLock when another process holds the lock.
+var errLocked = errors.New("locked")
+
+// LockedError is returned by AcquireLock when another process kept the lock
+// for longer than the timeout.
+type LockedError struct {
+	PID int // Process holding the lock, 0 if unknown
+}
+
+// Error returns the message of the LockedError.
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "ledger is locked by another process"
+	}
+	return fmt.Sprintf("ledger is locked by PID %d", e.PID)
+}
+
+// Lock is an advisory lock that gives one process at a time exclusive access
+// to a file, so that concurrent load-modify-save cycles do not overwrite each
+// other. Only processes that acquire the lock are excluded.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// AcquireLock takes the lock of the named file, waiting up to timeout for
+// another process to release it. The lock is held in a separate file with the
+// ".lock" suffix that records the PID of its holder. It returns a *LockedError
+// if the lock is still held when the timeout expires.
+func AcquireLock(filename string, timeout time.Duration) (*Lock, error) {
+	path := filename + lockSuffix
+	deadline := time.Now().Add(timeout)
+
+	for {
+		file, err := tryLock(path)
+		if err == nil {
+			// Record the holder of the lock for the error of other processes.
+			if err := file.Truncate(0); err == nil {
+				file.WriteString(strconv.Itoa(os.Getpid()))
+			}
+			return &Lock{file: file, path: path}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			return nil, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, &LockedError{PID: lockHolder(path)}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	return unlock(l.file, l.path)
+}
+
+// lockHolder returns the PID recorded in the lock file, or 0 if it cannot be read.
+func lockHolder(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
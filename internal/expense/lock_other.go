@@ -0,0 +1,37 @@
+//go:build !unix
+
+package expense
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLock creates the lock file exclusively. A lock file left behind by a
+// process that is no longer running is removed and the lock taken over.
+// It returns errLocked if another process holds the lock.
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+
+	// Take over the lock of a holder that exited without releasing it.
+	if pid := lockHolder(path); pid != 0 {
+		if _, err := os.FindProcess(pid); err != nil {
+			os.Remove(path)
+		}
+	}
+	return nil, errLocked
+}
+
+// unlock releases the lock by removing the lock file.
+func unlock(file *os.File, path string) error {
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,44 @@
+package expense_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.json")
+
+	lock, err := expense.AcquireLock(filename, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the lock can not be taken twice and names its holder.
+	_, err = expense.AcquireLock(filename, 100*time.Millisecond)
+	var locked *expense.LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected a locked error, but got %v instead", err)
+	}
+	if locked.PID != os.Getpid() {
+		t.Errorf("expected the lock to be held by PID %d, but got %d instead", os.Getpid(), locked.PID)
+	}
+
+	// Assert a waiting process gets the lock once it is released.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.Release()
+	}()
+
+	lock, err = expense.AcquireLock(filename, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
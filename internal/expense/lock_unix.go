@@ -0,0 +1,35 @@
+//go:build unix
+
+package expense
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock opens the lock file and takes an exclusive flock on it without
+// waiting. The kernel releases the lock when the process exits, so a crashed
+// process never leaves a stale lock behind. It returns errLocked if another
+// process holds the lock.
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// unlock releases the flock by closing the lock file. The file itself is kept,
+// since removing it could let two processes lock different files of the same name.
+func unlock(file *os.File, path string) error {
+	return file.Close()
+}
@@ -0,0 +1,118 @@
+package expense
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// centsPerUnit is the number of minor units in one major unit of money.
+const centsPerUnit = 100
+
+// Money represents an exact amount of money as an integer number of minor
+// units (cents). Arithmetic on Money is exact, unlike float64 amounts.
+type Money int64
+
+// ParseMoney parses a decimal amount such as "12", "12.5" or "-0.99" into Money.
+// It returns an error if the string is not a decimal number or has more than
+// two decimal places.
+func ParseMoney(s string) (Money, error) {
+	cents, err := parseCents(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if !cents.IsInt() {
+		return 0, inputErrorf("invalid amount %q: too many decimal places", s)
+	}
+	if !cents.Num().IsInt64() {
+		return 0, inputErrorf("invalid amount %q: out of range", s)
+	}
+	return Money(cents.Num().Int64()), nil
+}
+
+// parseCents parses the decimal number s exactly and returns it scaled to cents.
+func parseCents(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.Contains(s, "/") {
+		return nil, inputErrorf("invalid amount %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, inputErrorf("invalid amount %q", s)
+	}
+	return r.Mul(r, big.NewRat(centsPerUnit, 1)), nil
+}
+
+// String returns the amount as a decimal string with two decimal places.
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerUnit, cents%centsPerUnit)
+}
+
+// Set parses the string s into the Money. It allows Money to be used as a
+// command line flag.
+func (m *Money) Set(s string) error {
+	value, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+// MarshalJSON encodes the Money as a JSON number with two decimal places.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string into the Money. The number is
+// parsed from its literal text, so amounts saved as float64 by older versions
+// are read exactly; any digits beyond the cents are rounded half away from zero.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	cents, err := parseCents(s)
+	if err != nil {
+		return err
+	}
+
+	value, err := roundCents(cents)
+	if err != nil {
+		return err
+	}
+
+	*m = value
+	return nil
+}
+
+// roundCents rounds an amount of cents half away from zero to the nearest
+// whole cent.
+func roundCents(cents *big.Rat) (Money, error) {
+	num := new(big.Int).Abs(cents.Num())
+	quo, rem := new(big.Int).QuoRem(num, cents.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(cents.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if cents.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	if !quo.IsInt64() {
+		return 0, errors.New("invalid amount: out of range")
+	}
+	return Money(quo.Int64()), nil
+}
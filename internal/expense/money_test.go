@@ -0,0 +1,131 @@
+package expense_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected expense.Money
+		wantErr  bool
+	}{
+		{input: "20", expected: 20_00},
+		{input: "20.6", expected: 20_60},
+		{input: "20.60", expected: 20_60},
+		{input: "0.01", expected: 1},
+		{input: "-0.99", expected: -99},
+		{input: " 1200 ", expected: 1200_00},
+		{input: "1.005", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "twenty", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := expense.ParseMoney(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, but got %s instead", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %d, but got %d instead", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	testCases := []struct {
+		input    expense.Money
+		expected string
+	}{
+		{input: 0, expected: "0.00"},
+		{input: 5, expected: "0.05"},
+		{input: 20_60, expected: "20.60"},
+		{input: -1_50, expected: "-1.50"},
+	}
+
+	for _, tc := range testCases {
+		if tc.input.String() != tc.expected {
+			t.Errorf("expected %q, but got %q instead", tc.expected, tc.input.String())
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected expense.Money
+	}{
+		{input: `20.60`, expected: 20_60},
+		{input: `20.6`, expected: 20_60},
+		{input: `"20.60"`, expected: 20_60},
+		{input: `30.599999999999998`, expected: 30_60},
+		{input: `1e3`, expected: 1000_00},
+		{input: `-0.125`, expected: -13},
+	}
+
+	for _, tc := range testCases {
+		var got expense.Money
+		if err := json.Unmarshal([]byte(tc.input), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("%s: expected %d, but got %d instead", tc.input, tc.expected, got)
+		}
+	}
+
+	js, err := json.Marshal(expense.Money(20_60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(js) != "20.60" {
+		t.Errorf("expected %q, but got %q instead", "20.60", string(js))
+	}
+}
+
+func TestLoadFloatAmounts(t *testing.T) {
+	// Create a temp file holding an expense list saved with float amounts.
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := `[
+	{"id": 1, "date": "2024-08-06T10:00:00Z", "description": "lunch", "amount": 0.1},
+	{"id": 2, "date": "2024-08-06T11:00:00Z", "description": "coffee", "amount": 0.2},
+	{"id": 3, "date": "2024-08-06T12:00:00Z", "description": "dinner", "amount": 20.6}
+]`
+	if _, err := tempFile.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+
+	var expenseList expense.ExpenseList
+	if err := expenseList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("Total expenses: $%s\n", "20.90")
+
+	var buf bytes.Buffer
+	expenseList.Summary(&buf)
+
+	if expected != buf.String() {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+}
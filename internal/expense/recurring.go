@@ -0,0 +1,253 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Frequencies supported by a Schedule.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+// Schedule describes when a recurring expense occurs: every Interval days,
+// weeks, months or years, starting on the Start date. Monthly and yearly
+// occurrences fall on the day of month of Start, or on the last day of shorter months.
+type Schedule struct {
+	Frequency string    `json:"frequency"` // One of Daily, Weekly, Monthly or Yearly
+	Interval  int       `json:"interval"`  // Number of frequency units between occurrences
+	Start     time.Time `json:"start"`     // Date of the first occurrence
+}
+
+// String returns a human readable form of the schedule, such as "monthly" or "every 2 weeks".
+func (s Schedule) String() string {
+	if s.Interval == 1 {
+		return s.Frequency
+	}
+
+	unit := map[string]string{Daily: "days", Weekly: "weeks", Monthly: "months", Yearly: "years"}[s.Frequency]
+	return fmt.Sprintf("every %d %s", s.Interval, unit)
+}
+
+// validate checks that the schedule has a supported frequency and a positive interval.
+func (s Schedule) validate() error {
+	if !slices.Contains([]string{Daily, Weekly, Monthly, Yearly}, s.Frequency) {
+		return inputErrorf("invalid frequency %q: expected daily, weekly, monthly or yearly", s.Frequency)
+	}
+	if s.Interval < 1 {
+		return inputErrorf("invalid interval: interval must be positive")
+	}
+	return nil
+}
+
+// occurrence returns the date of the nth occurrence of the schedule, where
+// the first occurrence is the 0th.
+func (s Schedule) occurrence(n int) time.Time {
+	start := s.Start
+	switch s.Frequency {
+	case Daily:
+		return start.AddDate(0, 0, n*s.Interval)
+	case Weekly:
+		return start.AddDate(0, 0, 7*n*s.Interval)
+	}
+
+	months := n * s.Interval
+	if s.Frequency == Yearly {
+		months *= 12
+	}
+
+	// Clamp the day to the end of shorter months instead of letting
+	// time.Date roll it over into the following month.
+	first := time.Date(start.Year(), start.Month()+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(start.Day(), lastDay)-1)
+}
+
+// recurring represents a rule that adds an expense to the ExpenseList on every
+// occurrence of its schedule.
+type recurring struct {
+	ID          int      `json:"id"`                 // Unique identifier for the rule
+	Description string   `json:"description"`        // Description of the expenses
+	Category    string   `json:"category,omitempty"` // Category of the expenses
+	Amount      Money    `json:"amount"`             // Amount of the expenses
+	Currency    string   `json:"currency"`           // Currency of the amount
+	Schedule    Schedule `json:"schedule"`           // When the expenses occur
+	Count       int      `json:"count"`              // Number of occurrences already handled
+	Paused      bool     `json:"paused,omitempty"`   // Whether occurrences are skipped
+}
+
+// next returns the date of the next occurrence that has not been handled yet.
+func (r recurring) next() time.Time {
+	return r.Schedule.occurrence(r.Count)
+}
+
+// RecurringList represents a list of recurring expense rules.
+type RecurringList []recurring
+
+// Load reads recurring expense rules from the specified file and loads them into the RecurringList.
+// It returns an error if there is any issue reading or parsing the file.
+func (r *RecurringList) Load(filename string) error {
+	return loadJSON(filename, r)
+}
+
+// Save serializes the RecurringList to JSON format and writes it to the specified file.
+func (r *RecurringList) Save(filename string) error {
+	return saveJSON(filename, r)
+}
+
+// Add adds a new recurring expense rule with the given description, amount,
+// category, currency and schedule. It returns an error if the description is
+// empty, the amount is negative, the currency is invalid or the schedule is invalid.
+func (r *RecurringList) Add(description string, amount Money, category, currency string, schedule Schedule) error {
+	if description == "" {
+		return inputErrorf("description is empty")
+	}
+
+	if amount < 0 {
+		return inputErrorf("negative amount")
+	}
+
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+
+	schedule.Frequency = strings.ToLower(schedule.Frequency)
+	if err := schedule.validate(); err != nil {
+		return err
+	}
+
+	// Calculate the next id by adding the highest rule id + 1
+	id := 1
+	for _, item := range *r {
+		id = max(id, item.ID+1)
+	}
+
+	*r = append(*r, recurring{
+		ID:          id,
+		Description: strings.ToLower(description),
+		Category:    strings.ToLower(category),
+		Amount:      amount,
+		Currency:    currency,
+		Schedule:    schedule,
+	})
+	return nil
+}
+
+// Pause stops the rule with the specified ID from adding expenses until it is resumed.
+// It returns a *NotFoundError if no rule has the ID.
+func (r *RecurringList) Pause(id int) error {
+	index, err := r.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	(*r)[index].Paused = true
+	return nil
+}
+
+// Resume lets the paused rule with the specified ID add expenses again. The
+// occurrences that fell before now while the rule was paused are skipped.
+// It returns a *NotFoundError if no rule has the ID.
+func (r *RecurringList) Resume(id int, now time.Time) error {
+	index, err := r.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	item := &(*r)[index]
+	if !item.Paused {
+		return nil
+	}
+
+	item.Paused = false
+	for item.next().Before(now) {
+		item.Count++
+	}
+	return nil
+}
+
+// Delete removes the rule with the specified ID from the RecurringList.
+// Expenses it already added are kept. It returns a *NotFoundError if no rule
+// has the ID.
+func (r *RecurringList) Delete(id int) error {
+	index, err := r.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	*r = slices.Delete(*r, index, index+1)
+	return nil
+}
+
+// indexOf returns the position in the RecurringList of the rule with the
+// specified ID, or a *NotFoundError if no rule has the ID.
+func (r *RecurringList) indexOf(id int) (int, error) {
+	index := slices.IndexFunc(*r, func(item recurring) bool { return item.ID == id })
+	if index < 0 {
+		return 0, notFoundf("recurring expense not found: no rule with ID %d", id)
+	}
+	return index, nil
+}
+
+// List writes the recurring expense rules to the provided io.Writer in a tabular format.
+func (r *RecurringList) List(w io.Writer) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-6s%-40s%-16s%-20s%-14s%s\n", "ID", "Description", "Amount", "Schedule", "Next", "Status"))
+
+	for _, item := range *r {
+		status := "active"
+		if item.Paused {
+			status = "paused"
+		}
+
+		buf.WriteString(fmt.Sprintf("%-6d%-40s%-16s%-20s%-14s%s\n", item.ID, item.Description,
+			formatAmount(item.Amount, item.Currency), item.Schedule, item.next().Format("2006-01-02"), status))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// Materialize adds an expense to the ExpenseList for every occurrence of the
+// active rules that is due by now and has not been added yet, and returns the
+// number of expenses added. Each rule remembers how many of its occurrences
+// were handled, and an occurrence already present in the ExpenseList is never
+// added again, so running Materialize more than once is safe.
+func (r *RecurringList) Materialize(e *ExpenseList, now time.Time) (int, error) {
+	added := 0
+
+	for index := range *r {
+		item := &(*r)[index]
+		if item.Paused {
+			continue
+		}
+
+		for date := item.next(); !date.After(now); date = item.next() {
+			if !e.hasOccurrence(item.ID, date) {
+				if err := e.Add(item.Description, item.Amount, item.Category, item.Currency, date); err != nil {
+					return added, err
+				}
+				(*e)[len(*e)-1].RecurringID = item.ID
+				added++
+			}
+			item.Count++
+		}
+	}
+
+	return added, nil
+}
+
+// hasOccurrence reports whether the ExpenseList already holds the expense added
+// by the recurring rule with the given ID for the occurrence on the given date.
+func (e *ExpenseList) hasOccurrence(recurringID int, date time.Time) bool {
+	return slices.ContainsFunc(*e, func(item expense) bool {
+		return item.RecurringID == recurringID && item.Date.Equal(date)
+	})
+}
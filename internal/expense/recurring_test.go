@@ -0,0 +1,169 @@
+package expense_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+// date returns midnight of the given day in the local time zone.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func TestRecurringAdd(t *testing.T) {
+	var recurringList expense.RecurringList
+
+	testCases := []struct {
+		name     string
+		schedule expense.Schedule
+	}{
+		{name: "Frequency", schedule: expense.Schedule{Frequency: "hourly", Interval: 1, Start: date(2025, 1, 1)}},
+		{name: "Interval", schedule: expense.Schedule{Frequency: expense.Weekly, Interval: 0, Start: date(2025, 1, 1)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := recurringList.Add("Gym", 30_00, "", "", tc.schedule); err == nil {
+				t.Error("expected an error for an invalid schedule, but got nil instead")
+			}
+		})
+	}
+
+	if err := recurringList.Add("Gym", 30_00, "Health", "", expense.Schedule{Frequency: "Weekly", Interval: 2, Start: date(2025, 1, 1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-6s%-40s%-16s%-20s%-14s%s\n", "ID", "Description", "Amount", "Schedule", "Next", "Status"))
+	expectedBuf.WriteString(fmt.Sprintf("%-6d%-40s%-16s%-20s%-14s%s\n", 1, "gym", "$30.00", "every 2 weeks", "2025-01-01", "active"))
+
+	var gotBuf bytes.Buffer
+	recurringList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestRecurringSaveAndLoad(t *testing.T) {
+	// Create a temp file for holding the recurring expense rules.
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var recurringList expense.RecurringList
+	if err := recurringList.Add("Rent", 1200_00, "housing", "", expense.Schedule{Frequency: expense.Monthly, Interval: 1, Start: date(2025, 1, 1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Save the rules into the tempFile.
+	if err := recurringList.Save(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var newRecurringList expense.RecurringList
+
+	// Read the rules from the tempFile.
+	if err := newRecurringList.Load(tempFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedBuf, gotBuf bytes.Buffer
+	recurringList.List(&expectedBuf)
+	newRecurringList.List(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var recurringList expense.RecurringList
+
+	// Rent at the end of every month and the gym every two weeks.
+	if err := recurringList.Add("Rent", 1200_00, "housing", "", expense.Schedule{Frequency: expense.Monthly, Interval: 1, Start: date(2024, 1, 31)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recurringList.Add("Gym", 30_00, "health", "", expense.Schedule{Frequency: expense.Weekly, Interval: 2, Start: date(2024, 1, 1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := date(2024, 3, 31)
+	added, err := recurringList.Materialize(&expenseList, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rent on Jan 31, Feb 29 and Mar 31, the gym on Jan 1, 15, 29, Feb 12, 26, Mar 11 and 25.
+	if added != 10 {
+		t.Errorf("expected %d expenses to be added, but got %d instead", 10, added)
+	}
+
+	expected := []string{"2024-01-31", "2024-02-29", "2024-03-31"}
+	for index, want := range expected {
+		if got := expenseList[index].Date.Format("2006-01-02"); got != want {
+			t.Errorf("expected rent on %s, but got %s instead", want, got)
+		}
+	}
+
+	// Assert that materializing again does not add the same occurrences twice.
+	added, err = recurringList.Materialize(&expenseList, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 0 || len(expenseList) != 10 {
+		t.Errorf("expected no new expenses, but got %d added and %d in total", added, len(expenseList))
+	}
+
+	// Assert that a fresh copy of the rules does not duplicate the expenses either.
+	var staleList expense.RecurringList
+	if err := staleList.Add("Rent", 1200_00, "housing", "", expense.Schedule{Frequency: expense.Monthly, Interval: 1, Start: date(2024, 1, 31)}); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := staleList.Materialize(&expenseList, now); err != nil || added != 0 {
+		t.Errorf("expected no new expenses, but got %d added and error %v", added, err)
+	}
+}
+
+func TestRecurringPauseAndDelete(t *testing.T) {
+	var expenseList expense.ExpenseList
+	var recurringList expense.RecurringList
+
+	if err := recurringList.Add("Rent", 1200_00, "housing", "", expense.Schedule{Frequency: expense.Monthly, Interval: 1, Start: date(2024, 1, 1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paused rules add nothing.
+	if err := recurringList.Pause(1); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := recurringList.Materialize(&expenseList, date(2024, 3, 15)); err != nil || added != 0 {
+		t.Errorf("expected no expenses from a paused rule, but got %d added and error %v", added, err)
+	}
+
+	// Resuming skips the occurrences missed while paused.
+	if err := recurringList.Resume(1, date(2024, 3, 15)); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := recurringList.Materialize(&expenseList, date(2024, 4, 1)); err != nil || added != 1 {
+		t.Errorf("expected %d expense, but got %d added and error %v", 1, added, err)
+	}
+
+	if err := recurringList.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := recurringList.Delete(1); err == nil {
+		t.Error("expected an error for a missing rule, but got nil instead")
+	}
+	if len(expenseList) != 1 {
+		t.Errorf("expected length of the expense list: %d, but got %d instead", 1, len(expenseList))
+	}
+}
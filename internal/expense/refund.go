@@ -0,0 +1,89 @@
+package expense
+
+import (
+	"slices"
+	"time"
+)
+
+// Refund records a refund of the given amount of the expense with the specified
+// ID, such as the partial refund of a returned item. The refund is linked to
+// the expense and takes its date, category, currency, account and tags, so that
+// the summaries net it against the category and month of the purchase. The date
+// the refund was recorded is kept in its creation time, and the refund of a
+// split expense is spread over its parts in proportion to their amounts. An
+// empty description means "refund: " followed by the description of the
+// expense. It returns a *NotFoundError if no expense outside the trash has the
+// ID, or an *InputError if the record is not an expense or the amount is not
+// positive or exceeds what is left to refund.
+func (e *ExpenseList) Refund(id int, amount Money, description string) error {
+	original, err := e.Get(id)
+	if err != nil {
+		return err
+	}
+	if original.Kind != "" {
+		return inputErrorf("record %d is %s, only expenses can be refunded", id, original.kindName())
+	}
+	if amount <= 0 {
+		return inputErrorf("refund amount must be positive")
+	}
+	if left := original.Amount - e.refunded(id); amount > left {
+		return inputErrorf("refund of %s exceeds the %s left to refund on expense %d",
+			formatAmount(amount, original.currencyCode()), formatAmount(left, original.currencyCode()), id)
+	}
+
+	if description == "" {
+		description = "refund: " + original.Description
+	}
+	if err := e.Add(description, amount, original.Category, original.Currency, original.Date); err != nil {
+		return err
+	}
+
+	item := &(*e)[len(*e)-1]
+	item.Kind = KindRefund
+	item.RefundOf = id
+	item.Tags = original.Tags
+	item.Account = original.Account
+	item.Parts = apportion(amount, original.Parts)
+	return nil
+}
+
+// Refunds returns a new ExpenseList with the refunds outside the trash of the
+// expense with the specified ID.
+func (e *ExpenseList) Refunds(id int) ExpenseList {
+	return e.filter(func(item expense) bool { return !item.deleted() && item.refund() && item.RefundOf == id })
+}
+
+// refunded returns the total of the refunds outside the trash of the expense
+// with the specified ID.
+func (e *ExpenseList) refunded(id int) Money {
+	var total Money
+	for _, item := range e.Refunds(id) {
+		total += item.Amount
+	}
+	return total
+}
+
+// syncRefunds gives the refunds of the expense outside the trash its date and
+// category, and spreads them over its parts, so that they stay netted against
+// it. Refunds in the trash are synced when they are restored.
+func (e *ExpenseList) syncRefunds(original expense) {
+	for index, item := range *e {
+		if item.refund() && item.RefundOf == original.ID && !item.deleted() {
+			e.syncRefund(index, original)
+		}
+	}
+}
+
+// syncRefund gives the refund at the given position of the ExpenseList the
+// date and category of the original expense, and spreads it over its parts.
+func (e *ExpenseList) syncRefund(index int, original expense) {
+	item := (*e)[index]
+	parts := apportion(item.Amount, original.Parts)
+	if item.Date.Equal(original.Date) && item.Category == original.Category && slices.Equal(item.Parts, parts) {
+		return
+	}
+	(*e)[index].Date = original.Date
+	(*e)[index].Category = original.Category
+	(*e)[index].Parts = parts
+	(*e)[index].UpdatedAt = time.Now()
+}
@@ -0,0 +1,114 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestRefund(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	september := time.Date(2026, time.September, 20, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Supermarket", 120_00, "food", "", september); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Taxi", 20_00, "travel", "", september.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Salary", 3000_00, "work", "", september); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := expenseList.Refund(1, 30_00, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	refunds := expenseList.Refunds(1)
+	if len(refunds) != 1 {
+		t.Fatalf("expected 1 refund, but got %d instead", len(refunds))
+	}
+	if record := refunds[0].Record(); record.RefundOf != 1 || record.Kind != expense.KindRefund ||
+		record.Category != "food" || record.Date != "2026-09-20" || record.Description != "refund: supermarket" {
+		t.Errorf("expected a refund of expense 1 in food on 2026-09-20, but got %+v instead", record)
+	}
+
+	var input *expense.InputError
+	for _, test := range []struct {
+		id     int
+		amount expense.Money
+	}{
+		{id: 1, amount: 90_01},
+		{id: 1, amount: 0},
+		{id: 3, amount: 10_00},
+		{id: 4, amount: 10_00},
+	} {
+		if err := expenseList.Refund(test.id, test.amount, ""); !errors.As(err, &input) {
+			t.Errorf("expected an *InputError refunding %s of record %d, but got %v instead", test.amount, test.id, err)
+		}
+	}
+
+	var notFound *expense.NotFoundError
+	if err := expenseList.Refund(10, 10_00, ""); !errors.As(err, &notFound) {
+		t.Errorf("expected a *NotFoundError, but got %v instead", err)
+	}
+
+	if err := expenseList.Delete(1); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError deleting a refunded expense, but got %v instead", err)
+	}
+	if err := expenseList.Update(1, "", 20_00, "", time.Time{}); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError lowering the amount below the refunds, but got %v instead", err)
+	}
+	if err := expenseList.Update(4, "", 0, "", time.Time{}); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError updating a refund to zero, but got %v instead", err)
+	}
+}
+
+func TestRefundSummaries(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	september := time.Date(2026, time.September, 20, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Supermarket", 120_00, "food", "", september); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Taxi", 20_00, "travel", "", september.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Refund(1, 30_00, "returned bottles"); err != nil {
+		t.Fatal(err)
+	}
+
+	totals, err := expenseList.TotalsForMonth(2026, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Total != 90_00 {
+		t.Errorf("expected a total of %s for September, but got %s instead", expense.Money(90_00), totals.Total)
+	}
+
+	expected := []expense.Group{{Name: "food", Total: 90_00}, {Name: "travel", Total: 20_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) || byCategory.Total != 110_00 {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+
+	// Moving the expense moves its refund along with it.
+	if err := expenseList.Update(1, "", -1, "groceries", september.AddDate(0, -1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	expected = []expense.Group{{Name: "groceries", Total: 90_00}, {Name: "travel", Total: 20_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+
+	var buf bytes.Buffer
+	if err := expenseList.SummaryForMonth(&buf, 2026, 8); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Total expenses for August 2026: $90.00\n"; buf.String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+}
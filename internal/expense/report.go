@@ -0,0 +1,196 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Record is the machine-readable form of an expense, as written by the JSON output.
+type Record struct {
+	ID          int       `json:"id"`                     // Unique identifier for the expense
+	Date        string    `json:"date"`                   // Date when the expense was incurred, as YYYY-MM-DD
+	Description string    `json:"description"`            // Description of the expense
+	Category    string    `json:"category"`               // Category of the expense, "uncategorized" if it has none
+	Amount      Money     `json:"amount"`                 // Amount of the expense
+	Currency    string    `json:"currency"`               // Currency of the amount
+	Tags        []string  `json:"tags,omitempty"`         // Free-form labels of the expense
+	Account     string    `json:"account,omitempty"`      // Payment account the expense was paid from
+	Kind        string    `json:"kind"`                   // KindExpense, KindIncome or KindRefund
+	RefundOf    int       `json:"refund_of,omitempty"`    // ID of the expense a refund was made for
+	Parts       []Part    `json:"parts,omitempty"`        // Line items of a split expense
+	RecurringID int       `json:"recurring_id,omitempty"` // ID of the recurring rule that added the expense
+	CreatedAt   time.Time `json:"created_at,omitzero"`    // Date and time the expense was recorded
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`    // Date and time the expense was last modified
+	DeletedAt   time.Time `json:"deleted_at,omitzero"`    // Date and time the expense was moved to the trash
+}
+
+// Record returns the machine-readable form of the expense.
+func (e expense) Record() Record {
+	return Record{
+		ID:          e.ID,
+		Date:        e.Date.Format("2006-01-02"),
+		Description: e.Description,
+		Category:    e.categoryName(),
+		Amount:      e.Amount,
+		Currency:    e.currencyCode(),
+		Tags:        e.Tags,
+		Account:     e.Account,
+		Kind:        e.kindName(),
+		RefundOf:    e.RefundOf,
+		Parts:       e.Parts,
+		RecurringID: e.RecurringID,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+		DeletedAt:   e.DeletedAt,
+	}
+}
+
+// Records returns the machine-readable form of the expenses that are not in
+// the trash, in the order they are listed.
+func (e *ExpenseList) Records() []Record {
+	records := []Record{}
+	for _, item := range e.active() {
+		records = append(records, item.Record())
+	}
+	return records
+}
+
+// Totals holds the figures of a summary of expenses, as written by the
+// Summary methods and by the JSON output. Income is left out of the total and
+// of the groups, and reported on its own. Refunds are taken off the total and
+// the groups of the expense they refund.
+type Totals struct {
+	Currency string         `json:"currency"`          // Currency of the totals
+	Year     int            `json:"year,omitempty"`    // Year summarized, zero if the summary is not for a year
+	Month    int            `json:"month,omitempty"`   // Month summarized, zero if the summary is not for a month
+	Total    Money          `json:"total"`             // Total of the summarized expenses
+	Income   Money          `json:"income"`            // Total of the summarized income
+	Net      Money          `json:"net"`               // Income less expenses, negative if more was spent than earned
+	Groups   []Group        `json:"groups,omitempty"`  // Totals of the months or categories, for a grouped summary
+	Budgets  []BudgetStatus `json:"budgets,omitempty"` // Status of the budgets, for a summary of a month
+}
+
+// Group holds the total of a group of expenses, such as a month or a category.
+type Group struct {
+	Name  string `json:"name"`  // Name of the month or category
+	Total Money  `json:"total"` // Total of the expenses in the group
+}
+
+// Totals returns the total of all the expenses and of all the income.
+func (e *ExpenseList) Totals() Totals {
+	return e.totals(func(item expense) bool { return true })
+}
+
+// TotalsForMonth returns the total of the expenses and of the income in the
+// given month of the given year. The month parameter should be an integer
+// between 1 and 12. If the month is out of range, an error is returned.
+func (e *ExpenseList) TotalsForMonth(year, month int) (Totals, error) {
+	if month < 1 || month > 12 {
+		return Totals{}, inputErrorf("invalid month: month is out of range")
+	}
+
+	totals := e.totals(func(item expense) bool {
+		return item.Date.Year() == year && item.Date.Month() == time.Month(month)
+	})
+	totals.Year, totals.Month = year, month
+	return totals, nil
+}
+
+// TotalsForYear returns the total of the expenses and of the income in the given year.
+func (e *ExpenseList) TotalsForYear(year int) Totals {
+	totals := e.totals(func(item expense) bool { return item.Date.Year() == year })
+	totals.Year = year
+	return totals
+}
+
+// TotalsByMonth returns the total of every month of the given year, and of the year.
+func (e *ExpenseList) TotalsByMonth(year int) Totals {
+	totals := e.TotalsForYear(year)
+	for month := time.January; month <= time.December; month++ {
+		totals.Groups = append(totals.Groups, Group{Name: month.String(), Total: e.spent(year, month, "")})
+	}
+	return totals
+}
+
+// TotalsByCategory returns the total of every category, sorted alphabetically,
+// and the grand total. Expenses without a category are grouped as "uncategorized",
+// and each part of a split expense counts toward its own category.
+func (e *ExpenseList) TotalsByCategory() Totals {
+	categories := make(map[string]Money)
+	totals := e.Totals()
+
+	for _, item := range e.spending() {
+		for _, part := range item.categoryParts() {
+			categories[part.categoryName()] += part.Amount
+		}
+	}
+
+	for _, category := range slices.Sorted(maps.Keys(categories)) {
+		totals.Groups = append(totals.Groups, Group{Name: category, Total: categories[category]})
+	}
+	return totals
+}
+
+// totals returns the total of the expenses and of the income outside the
+// trash for which keep returns true.
+func (e *ExpenseList) totals(keep func(item expense) bool) Totals {
+	totals := Totals{Currency: e.currency()}
+	for _, item := range e.active() {
+		switch {
+		case !keep(item):
+		case item.income():
+			totals.Income += item.Amount
+		case item.refund():
+			totals.Total -= item.Amount
+		default:
+			totals.Total += item.Amount
+		}
+	}
+	totals.Net = totals.Income - totals.Total
+	return totals
+}
+
+// WriteSummary writes the total expenses to the provided io.Writer, followed by
+// the total income and the net amount if withIncome is true. The labels name the
+// month or the year the totals are for, if any, such as "for August 2024".
+func (t Totals) WriteSummary(w io.Writer, withIncome bool) {
+	var period string
+	switch {
+	case t.Month != 0:
+		period = fmt.Sprintf(" for %s %d", time.Month(t.Month), t.Year)
+	case t.Year != 0:
+		period = fmt.Sprintf(" for %d", t.Year)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("Total expenses%s: %s\n", period, formatAmount(t.Total, t.Currency)))
+	if withIncome {
+		buf.WriteString(fmt.Sprintf("Total income%s: %s\n", period, formatAmount(t.Income, t.Currency)))
+		buf.WriteString(fmt.Sprintf("Net%s: %s\n", period, formatAmount(t.Net, t.Currency)))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// WriteTable writes the totals of the groups to the provided io.Writer in a
+// tabular format under the given heading, followed by the total, labelled with
+// the year the totals are for, if any.
+func (t Totals) WriteTable(w io.Writer, heading string) {
+	label := "Total"
+	if t.Year != 0 {
+		label = fmt.Sprintf("Total %d", t.Year)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-20s%s\n", heading, "Total"))
+	for _, group := range t.Groups {
+		buf.WriteString(fmt.Sprintf("%-20s%s\n", group.Name, formatAmount(group.Total, t.Currency)))
+	}
+	buf.WriteString(fmt.Sprintf("%-20s%s\n", label, formatAmount(t.Total, t.Currency)))
+
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,124 @@
+package expense_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestRecords(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	date := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Lunch", 12_50, "Food", "", date); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Taxi", 5_00, "", "eur", date); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	records := expenseList.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected %d record, but got %d instead", 1, len(records))
+	}
+
+	data, err := json.Marshal(records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{"id": 2.0, "date": "2024-08-06", "description": "taxi", "category": "uncategorized", "amount": 5.0, "currency": "EUR"}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("expected %s to be %v, but got %v instead", key, value, got[key])
+		}
+	}
+	if _, ok := got["deleted_at"]; ok {
+		t.Errorf("expected no deleted_at for an expense outside the trash, but got %v instead", got["deleted_at"])
+	}
+}
+
+func TestTotals(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	august := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Lunch", 20_00, "food", "", august); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Taxi", 15_00, "travel", "", august.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Dinner", 30_00, "food", "", august.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if totals := expenseList.Totals(); totals.Total != 65_00 || totals.Currency != "USD" {
+		t.Errorf("expected a total of %s USD, but got %+v instead", expense.Money(65_00), totals)
+	}
+	if totals := expenseList.TotalsForYear(2024); totals.Total != 35_00 || totals.Year != 2024 {
+		t.Errorf("expected a total of %s for 2024, but got %+v instead", expense.Money(35_00), totals)
+	}
+
+	totals, err := expenseList.TotalsForMonth(2024, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Total != 20_00 || totals.Month != 8 {
+		t.Errorf("expected a total of %s for August, but got %+v instead", expense.Money(20_00), totals)
+	}
+
+	var input *expense.InputError
+	if _, err := expenseList.TotalsForMonth(2024, 0); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for an invalid month, but got %v instead", err)
+	}
+
+	byMonth := expenseList.TotalsByMonth(2024)
+	if len(byMonth.Groups) != 12 || byMonth.Groups[8] != (expense.Group{Name: "September", Total: 15_00}) {
+		t.Errorf("expected a total of %s for September, but got %+v instead", expense.Money(15_00), byMonth.Groups)
+	}
+
+	expected := []expense.Group{{Name: "food", Total: 50_00}, {Name: "travel", Total: 15_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+}
+
+func TestTotalsWithIncome(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	october := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Rent", 800_00, "housing", "", october); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Salary", 3000_00, "work", "", october); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.AddIncome("Bonus", 500_00, "work", "", october.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	totals, err := expenseList.TotalsForMonth(2026, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Total != 800_00 || totals.Income != 3000_00 || totals.Net != 2200_00 {
+		t.Errorf("expected expenses of %s, income of %s and a net of %s, but got %+v instead",
+			expense.Money(800_00), expense.Money(3000_00), expense.Money(2200_00), totals)
+	}
+
+	if records := expenseList.Records(); records[1].Kind != expense.KindIncome || records[0].Kind != expense.KindExpense {
+		t.Errorf("expected an expense and an income, but got %+v instead", records)
+	}
+}
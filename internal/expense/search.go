@@ -0,0 +1,150 @@
+package expense
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores of the ways a search term can match a word of a description. A term
+// with typos scores less the more edits it takes to turn it into the word.
+const (
+	exactScore     = 1.0
+	prefixScore    = 0.8
+	substringScore = 0.6
+	typoScore      = 0.5
+)
+
+// Search returns the expenses outside the trash whose description matches every
+// one of the search terms, best matches first. A term matches a word of the
+// description that is equal to it, starts with it, contains it or is a few typos
+// away from it, so "starbuks" finds "starbucks coffee". Expenses that match
+// equally well keep their order. It returns an *InputError if there are no terms.
+func (e *ExpenseList) Search(terms string) (ExpenseList, error) {
+	words := splitWords(terms)
+	if len(words) == 0 {
+		return nil, inputErrorf("search terms are empty")
+	}
+
+	type match struct {
+		item  expense
+		score float64
+	}
+
+	var matches []match
+	for _, item := range e.active() {
+		if score := searchScore(words, splitWords(item.Description)); score > 0 {
+			matches = append(matches, match{item: item, score: score})
+		}
+	}
+	slices.SortStableFunc(matches, func(x, y match) int {
+		switch {
+		case x.score > y.score:
+			return -1
+		case x.score < y.score:
+			return 1
+		}
+		return 0
+	})
+
+	found := make(ExpenseList, 0, len(matches))
+	for _, m := range matches {
+		found = append(found, m.item)
+	}
+	return found, nil
+}
+
+// searchScore returns the average score of the terms against the words of a
+// description, or zero if any term matches none of the words.
+func searchScore(terms, words []string) float64 {
+	total := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, word := range words {
+			best = max(best, termScore(term, word))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(terms))
+}
+
+// termScore returns how well the search term matches the word, from zero for
+// no match to exactScore for an exact match.
+func termScore(term, word string) float64 {
+	switch {
+	case term == word:
+		return exactScore
+	case strings.HasPrefix(word, term):
+		return prefixScore
+	case len(term) >= 3 && strings.Contains(word, term):
+		return substringScore
+	}
+
+	// Tolerate typos in the whole word, or in the start of it so that a
+	// misspelled prefix still matches.
+	t, w := []rune(term), []rune(word)
+	allowed := allowedTypos(len(t))
+	if allowed == 0 {
+		return 0
+	}
+	distance := editDistance(t, w)
+	if len(w) > len(t) {
+		distance = min(distance, editDistance(t, w[:len(t)]))
+	}
+	if distance > allowed {
+		return 0
+	}
+	return typoScore * (1 - float64(distance)/float64(len(t)+1))
+}
+
+// allowedTypos returns the number of typos tolerated in a search term of the
+// given length: none in short terms, where a typo changes too much of the term.
+func allowedTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and
+// transpositions of adjacent letters needed to turn a into b.
+func editDistance(a, b []rune) int {
+	// rows holds the distances of the prefixes of a, two rows back, one row back
+	// and the current row, to every prefix of b.
+	rows := [3][]int{make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)}
+	for j := range rows[1] {
+		rows[1][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		previous2, previous, current := rows[0], rows[1], rows[2]
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		rows[0], rows[1], rows[2] = previous, current, previous2
+	}
+
+	return rows[1][len(b)]
+}
+
+// splitWords splits the text into lower case words made of letters and digits.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,48 @@
+package expense_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestSearch(t *testing.T) {
+	var expenseList expense.ExpenseList
+	for _, description := range []string{"Starbucks coffee", "Star market groceries", "Coffee beans", "Uber to airport", "Starbucks mug"} {
+		if err := expenseList.Add(description, 5_00, "", "", time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expenseList.Delete(5)
+
+	testCases := []struct {
+		terms    string
+		expected []int
+	}{
+		{terms: "starbuks", expected: []int{1}},
+		{terms: "STAR", expected: []int{2, 1}},
+		{terms: "cofee", expected: []int{1, 3}},
+		{terms: "coffee", expected: []int{1, 3}},
+		{terms: "beans coffee", expected: []int{3}},
+		{terms: "airprot", expected: []int{4}},
+		{terms: "taxi", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		found, err := expenseList.Search(tc.terms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := ids(found); !slices.Equal(ids, tc.expected) {
+			t.Errorf("expected searching %q to find %v, but got %v instead", tc.terms, tc.expected, ids)
+		}
+	}
+
+	var input *expense.InputError
+	if _, err := expenseList.Search("  "); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for empty terms, but got %v instead", err)
+	}
+}
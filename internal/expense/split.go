@@ -0,0 +1,136 @@
+package expense
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Part is a line item of a split expense, such as the groceries on a
+// supermarket receipt that also holds household goods.
+type Part struct {
+	Category string `json:"category"` // Category of the line item
+	Amount   Money  `json:"amount"`   // Amount of the line item, in the currency of the expense
+}
+
+// categoryName returns the category of the part, or "uncategorized" if the
+// part has no category.
+func (p Part) categoryName() string {
+	return expense{Category: p.Category}.categoryName()
+}
+
+// String returns the part as category=amount, such as "groceries=90.00".
+func (p Part) String() string {
+	return fmt.Sprintf("%s=%s", p.categoryName(), p.Amount)
+}
+
+// ParsePart parses a line item written as category=amount, such as
+// "groceries=90" or "household=29.99". It returns an *InputError if the
+// category is empty or the amount is invalid.
+func ParsePart(s string) (Part, error) {
+	category, amount, ok := strings.Cut(s, "=")
+	category = strings.ToLower(strings.TrimSpace(category))
+	if !ok || category == "" {
+		return Part{}, inputErrorf("invalid part %q: expected category=amount", s)
+	}
+
+	parsed, err := ParseMoney(strings.TrimSpace(amount))
+	if err != nil {
+		return Part{}, err
+	}
+	return Part{Category: category, Amount: parsed}, nil
+}
+
+// Split splits the expense with the specified ID into the given line items,
+// replacing any previous split, so that the summaries by category and the
+// budgets count each part in its own category. The parts must be positive and
+// add up exactly to the amount of the expense. No parts removes the split. The
+// refunds of the expense are spread over its parts in proportion to their
+// amounts. It returns a *NotFoundError if no expense outside the trash has the
+// ID, or an *InputError if the record is not an expense or the parts are invalid.
+func (e *ExpenseList) Split(id int, parts []Part) error {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return err
+	}
+	item := &(*e)[index]
+	if item.Kind != "" {
+		return inputErrorf("record %d is %s, only expenses can be split", id, item.kindName())
+	}
+
+	if len(parts) == 1 {
+		return inputErrorf("a split needs at least two parts")
+	}
+
+	normalized := make([]Part, 0, len(parts))
+	var total Money
+	for _, part := range parts {
+		if part.Amount <= 0 {
+			return inputErrorf("invalid part %s: amount must be positive", part)
+		}
+		total += part.Amount
+		normalized = append(normalized, Part{Category: strings.ToLower(part.Category), Amount: part.Amount})
+	}
+	if len(normalized) == 0 {
+		normalized = nil
+	} else if total != item.Amount {
+		return inputErrorf("parts add up to %s instead of the %s of expense %d",
+			formatAmount(total, item.currencyCode()), formatAmount(item.Amount, item.currencyCode()), id)
+	}
+
+	if slices.Equal(item.Parts, normalized) {
+		return nil
+	}
+	item.Parts = normalized
+	item.UpdatedAt = time.Now()
+	e.syncRefunds(*item)
+	return nil
+}
+
+// categoryParts returns the parts of the expense, or a single part holding
+// its category and amount if the expense is not split.
+func (e expense) categoryParts() []Part {
+	if len(e.Parts) == 0 {
+		return []Part{{Category: e.Category, Amount: e.Amount}}
+	}
+	return e.Parts
+}
+
+// formatParts returns the parts separated by commas, or "none" if there are no parts.
+func formatParts(parts []Part) string {
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	formatted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		formatted = append(formatted, part.String())
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// apportion spreads the amount over parts in proportion to their amounts,
+// giving the cents left over by rounding to the first parts, so that the new
+// parts add up exactly to the amount. It returns nil if there are no parts.
+func apportion(amount Money, parts []Part) []Part {
+	var total Money
+	for _, part := range parts {
+		total += part.Amount
+	}
+	if len(parts) == 0 || total == 0 {
+		return nil
+	}
+
+	shares := make([]Part, len(parts))
+	left := amount
+	for index, part := range parts {
+		shares[index] = Part{Category: part.Category, Amount: amount * part.Amount / total}
+		left -= shares[index].Amount
+	}
+	for index := 0; left > 0; index = (index + 1) % len(shares) {
+		shares[index].Amount++
+		left--
+	}
+	return shares
+}
@@ -0,0 +1,82 @@
+package expense_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestParsePart(t *testing.T) {
+	part, err := expense.ParsePart("Groceries=90.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (expense.Part{Category: "groceries", Amount: 90_50}); part != expected {
+		t.Errorf("expected %+v, but got %+v instead", expected, part)
+	}
+
+	var input *expense.InputError
+	for _, value := range []string{"groceries", "=90", "groceries=ninety"} {
+		if _, err := expense.ParsePart(value); !errors.As(err, &input) {
+			t.Errorf("expected an *InputError parsing %q, but got %v instead", value, err)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	september := time.Date(2026, time.September, 20, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Add("Supermarket", 120_00, "shopping", "", september); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Add("Taxi", 20_00, "travel", "", september); err != nil {
+		t.Fatal(err)
+	}
+
+	var input *expense.InputError
+	for _, parts := range [][]expense.Part{
+		{{Category: "groceries", Amount: 90_00}, {Category: "household", Amount: 20_00}},
+		{{Category: "groceries", Amount: 120_00}},
+		{{Category: "groceries", Amount: 130_00}, {Category: "household", Amount: -10_00}},
+	} {
+		if err := expenseList.Split(1, parts); !errors.As(err, &input) {
+			t.Errorf("expected an *InputError splitting into %v, but got %v instead", parts, err)
+		}
+	}
+
+	parts := []expense.Part{{Category: "groceries", Amount: 90_00}, {Category: "household", Amount: 30_00}}
+	if err := expenseList.Split(1, parts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []expense.Group{{Name: "groceries", Total: 90_00}, {Name: "household", Total: 30_00}, {Name: "travel", Total: 20_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) || byCategory.Total != 140_00 {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+
+	// A refund is spread over the parts, to the cent.
+	if err := expenseList.Refund(1, 25_01, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected = []expense.Group{{Name: "groceries", Total: 71_24}, {Name: "household", Total: 23_75}, {Name: "travel", Total: 20_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+
+	if err := expenseList.Update(1, "", 100_00, "", time.Time{}); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError changing the amount of a split expense, but got %v instead", err)
+	}
+
+	// Removing the split counts the expense in its category again.
+	if err := expenseList.Split(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected = []expense.Group{{Name: "shopping", Total: 94_99}, {Name: "travel", Total: 20_00}}
+	if byCategory := expenseList.TotalsByCategory(); fmt.Sprint(byCategory.Groups) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, but got %v instead", expected, byCategory.Groups)
+	}
+}
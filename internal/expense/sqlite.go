@@ -0,0 +1,156 @@
+package expense
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	_ "modernc.org/sqlite" // Registers the pure Go "sqlite" driver.
+)
+
+// sqliteSchema creates the table of expenses and its indexes. Every expense is
+// kept as its JSON encoding, next to the columns queries filter on, so that
+// new fields of an expense need no change to the schema.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS expenses (
+	id       INTEGER PRIMARY KEY,
+	date     INTEGER NOT NULL,
+	category TEXT    NOT NULL,
+	data     TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS expenses_date ON expenses (date);
+CREATE INDEX IF NOT EXISTS expenses_category ON expenses (category);
+`
+
+// SQLiteStore is a Store that keeps the expenses in a SQLite database, which
+// is changed one row at a time.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the SQLite database in the named file, creating it if it
+// does not exist.
+func OpenSQLite(filename string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000;" + sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load returns every expense in the database.
+func (s *SQLiteStore) Load() (ExpenseList, error) {
+	return s.Query(Query{})
+}
+
+// Append adds the expenses to the database in a single transaction.
+func (s *SQLiteStore) Append(items ExpenseList) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO expenses (id, date, category, data) VALUES (?, ?, ?, ?)",
+			item.ID, item.Date.UnixNano(), item.categoryName(), string(data)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Update replaces the expense in the database that has the ID of the given one.
+// It returns a *NotFoundError if no expense has the ID.
+func (s *SQLiteStore) Update(item expense) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec("UPDATE expenses SET date = ?, category = ?, data = ? WHERE id = ?",
+		item.Date.UnixNano(), item.categoryName(), string(data), item.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result, item.ID)
+}
+
+// Delete removes the expense with the specified ID from the database. It
+// returns a *NotFoundError if no expense has the ID.
+func (s *SQLiteStore) Delete(id int) error {
+	result, err := s.db.Exec("DELETE FROM expenses WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result, id)
+}
+
+// Query returns the expenses in the database that match the query, using the
+// indexes on date and category.
+func (s *SQLiteStore) Query(q Query) (ExpenseList, error) {
+	query := "SELECT data FROM expenses WHERE 1 = 1"
+	var args []any
+
+	if !q.From.IsZero() {
+		query += " AND date >= ?"
+		args = append(args, q.From.UnixNano())
+	}
+	if !q.To.IsZero() {
+		query += " AND date < ?"
+		args = append(args, q.To.UnixNano())
+	}
+	if q.Category != "" {
+		query += " AND category = ?"
+		args = append(args, expense{Category: strings.ToLower(q.Category)}.categoryName())
+	}
+
+	rows, err := s.db.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	e := ExpenseList{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var item expense
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, err
+		}
+		e = append(e, item)
+	}
+	return e, rows.Err()
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// checkAffected returns a *NotFoundError if the statement changed no row.
+func checkAffected(result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &NotFoundError{ID: id}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package expense_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := expense.OpenSQLite(filepath.Join(t.TempDir(), "expenses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestSQLiteStoreReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "expenses.db")
+
+	store, err := expense.OpenSQLite(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", date)
+	if err := store.Append(e); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Assert the expenses outlive the connection.
+	store, err = expense.OpenSQLite(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].Amount != 20_00 || !loaded[0].Date.Equal(date) {
+		t.Errorf("expected the stored expense, but got %v instead", loaded)
+	}
+}
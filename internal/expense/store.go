@@ -0,0 +1,161 @@
+package expense
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Store is a persistent collection of expenses. Changes are written as they
+// are made, so a Store never needs to be saved as a whole.
+type Store interface {
+	// Load returns every expense in the Store, ordered by ID.
+	Load() (ExpenseList, error)
+	// Append adds the expenses to the Store.
+	Append(items ExpenseList) error
+	// Update replaces the expense in the Store that has the ID of the given one.
+	Update(item expense) error
+	// Delete removes the expense with the specified ID from the Store.
+	Delete(id int) error
+	// Query returns the expenses that match the query, ordered by ID.
+	Query(q Query) (ExpenseList, error)
+	// Close releases the resources held by the Store.
+	Close() error
+}
+
+// Query selects the expenses returned by Store.Query. A zero field matches every expense.
+type Query struct {
+	From     time.Time // Only expenses on or after From
+	To       time.Time // Only expenses before To
+	Category string    // Only expenses in Category, "uncategorized" for expenses without one
+}
+
+// matches reports whether the expense is selected by the query.
+func (q Query) matches(item expense) bool {
+	if !q.From.IsZero() && item.Date.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !item.Date.Before(q.To) {
+		return false
+	}
+	return q.Category == "" || strings.ToLower(q.Category) == item.categoryName()
+}
+
+// OpenStore opens the Store kept in the named file. Files with the ".db",
+// ".sqlite" or ".sqlite3" extension are SQLite databases, files with the
+// ".jsonl" or ".journal" extension are journals and other files are JSON files.
+func OpenStore(filename string) (Store, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return OpenSQLite(filename)
+	case ".jsonl", ".journal":
+		return NewJournalStore(filename), nil
+	default:
+		return NewFileStore(filename), nil
+	}
+}
+
+// FileStore is a Store that keeps the expenses in a JSON file, which is
+// rewritten as a whole on every change.
+type FileStore struct {
+	filename string
+}
+
+// NewFileStore returns the FileStore that keeps the expenses in the named file.
+func NewFileStore(filename string) *FileStore {
+	return &FileStore{filename: filename}
+}
+
+// Load returns every expense in the file.
+func (s *FileStore) Load() (ExpenseList, error) {
+	var e ExpenseList
+	if err := e.Load(s.filename); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Append adds the expenses to the file.
+func (s *FileStore) Append(items ExpenseList) error {
+	return s.change(func(e *ExpenseList) error {
+		*e = append(*e, items...)
+		return nil
+	})
+}
+
+// Update replaces the expense in the file that has the ID of the given one.
+// It returns a *NotFoundError if no expense has the ID.
+func (s *FileStore) Update(item expense) error {
+	return s.change(func(e *ExpenseList) error {
+		index, err := e.indexOf(item.ID)
+		if err != nil {
+			return err
+		}
+		(*e)[index] = item
+		return nil
+	})
+}
+
+// Delete removes the expense with the specified ID from the file. It returns
+// a *NotFoundError if no expense has the ID.
+func (s *FileStore) Delete(id int) error {
+	return s.change(func(e *ExpenseList) error {
+		index, err := e.indexOf(id)
+		if err != nil {
+			return err
+		}
+		*e = slices.Delete(*e, index, index+1)
+		return nil
+	})
+}
+
+// Query returns the expenses in the file that match the query.
+func (s *FileStore) Query(q Query) (ExpenseList, error) {
+	e, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return e.filter(q.matches), nil
+}
+
+// Close does nothing, as the file is only open while it is read or written.
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// change loads the expenses, applies the change to them and saves them.
+func (s *FileStore) change(apply func(e *ExpenseList) error) error {
+	e, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := apply(&e); err != nil {
+		return err
+	}
+	return e.Save(s.filename)
+}
+
+// Migrate copies every expense from one Store into another, keeping their IDs,
+// and returns the number of expenses copied. It returns an error if the
+// destination already holds expenses.
+func Migrate(from, to Store) (int, error) {
+	existing, err := to.Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, fmt.Errorf("cannot migrate: the destination already holds %d expenses", len(existing))
+	}
+
+	e, err := from.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := to.Append(e); err != nil {
+		return 0, err
+	}
+	return len(e), nil
+}
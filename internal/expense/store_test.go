@@ -0,0 +1,166 @@
+package expense_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+// testStore runs the operations every Store supports against the store.
+func testStore(t *testing.T, store expense.Store) {
+	t.Helper()
+
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local))
+	e.Add("taxi", 15_00, "travel", "", time.Date(2024, time.August, 20, 0, 0, 0, 0, time.Local))
+	e.Add("dinner", 30_00, "food", "EUR", time.Date(2024, time.September, 1, 0, 0, 0, 0, time.Local))
+	e.Add("stamps", 5_00, "", "", time.Date(2024, time.September, 2, 0, 0, 0, 0, time.Local))
+
+	if err := store.Append(e[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(e[2:]); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(e) {
+		t.Fatalf("expected %d expenses, but got %d instead", len(e), len(loaded))
+	}
+	for i := range e {
+		if loaded[i].ID != e[i].ID || loaded[i].Description != e[i].Description || !loaded[i].Date.Equal(e[i].Date) {
+			t.Errorf("expected %v, but got %v instead", e[i], loaded[i])
+		}
+	}
+
+	if err := e.Update(2, "", 18_00, "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := e.Get(2)
+	if err := store.Update(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var notFound *expense.NotFoundError
+	if err := store.Delete(1); !errors.As(err, &notFound) {
+		t.Errorf("expected a not found error, but got %v instead", err)
+	}
+
+	testCases := []struct {
+		name     string
+		query    expense.Query
+		expected []int
+	}{
+		{name: "All", query: expense.Query{}, expected: []int{2, 3, 4}},
+		{name: "From", query: expense.Query{From: time.Date(2024, time.September, 1, 0, 0, 0, 0, time.Local)}, expected: []int{3, 4}},
+		{name: "To", query: expense.Query{To: time.Date(2024, time.September, 2, 0, 0, 0, 0, time.Local)}, expected: []int{2, 3}},
+		{name: "Category", query: expense.Query{Category: "Food"}, expected: []int{3}},
+		{name: "Uncategorized", query: expense.Query{Category: "uncategorized"}, expected: []int{4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := store.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []int
+			for _, item := range result {
+				ids = append(ids, item.ID)
+			}
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("expected IDs %v, but got %v instead", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Errorf("expected IDs %v, but got %v instead", tc.expected, ids)
+				}
+			}
+		})
+	}
+
+	result, err := store.Query(expense.Query{Category: "travel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Amount != 18_00 {
+		t.Errorf("expected the updated expense, but got %v instead", result)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := expense.NewFileStore(filepath.Join(t.TempDir(), "expenses.json"))
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestOpenStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := expense.OpenStore(filepath.Join(dir, "expenses.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*expense.FileStore); !ok {
+		t.Errorf("expected a file store, but got %T instead", store)
+	}
+
+	store, err = expense.OpenStore(filepath.Join(dir, "expenses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, ok := store.(*expense.SQLiteStore); !ok {
+		t.Errorf("expected a SQLite store, but got %T instead", store)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+
+	from := expense.NewFileStore(filepath.Join(dir, "expenses.json"))
+	var e expense.ExpenseList
+	e.Add("lunch", 20_00, "food", "", time.Time{})
+	e.Add("taxi", 15_00, "travel", "", time.Time{})
+	if err := from.Append(e); err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := expense.OpenSQLite(filepath.Join(dir, "expenses.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer to.Close()
+
+	migrated, err := expense.Migrate(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 2 {
+		t.Errorf("expected %d expenses migrated, but got %d instead", 2, migrated)
+	}
+
+	loaded, err := to.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[1].Description != "taxi" {
+		t.Errorf("expected the migrated expenses, but got %v instead", loaded)
+	}
+
+	// Assert the expenses are not migrated twice.
+	if _, err := expense.Migrate(from, to); err == nil {
+		t.Error("expected an error migrating into a database that holds expenses")
+	}
+}
@@ -0,0 +1,142 @@
+package expense
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// untagged is the tag reported for expenses without tags.
+const untagged = "untagged"
+
+// normalizeTags returns the tags in lower case, sorted and without duplicates.
+// It returns an *InputError if a tag is empty or contains spaces or commas.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.IndexFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) >= 0 {
+			return nil, inputErrorf("invalid tag %q: expected a word without spaces or commas", tag)
+		}
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// hasTags reports whether the expense has every one of the tags.
+func (e expense) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTags replaces the tags of the expense with the specified ID. Tags are
+// stored in lower case and sorted, without duplicates, and the modification
+// time of the expense is set if they changed. It returns a
+// *NotFoundError if no expense outside the trash has the ID, or an *InputError
+// if a tag is empty or contains spaces or commas.
+func (e *ExpenseList) SetTags(id int, tags []string) error {
+	index, err := e.activeIndexOf(id)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+
+	if slices.Equal((*e)[index].Tags, normalized) {
+		return nil
+	}
+	(*e)[index].Tags = normalized
+	(*e)[index].UpdatedAt = time.Now()
+	return nil
+}
+
+// Tagged returns a new ExpenseList with the expenses outside the trash that
+// have every one of the tags. It returns an *InputError if a tag is invalid.
+func (e *ExpenseList) Tagged(tags []string) (ExpenseList, error) {
+	normalized, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	return e.filter(func(item expense) bool { return !item.deleted() && item.hasTags(normalized) }), nil
+}
+
+// RenameTag replaces the tag from with the tag to on every expense, in the
+// trash or not, and returns the expenses that were changed. An expense that
+// already has the new tag keeps it once. It returns a *NotFoundError if no
+// expense has the tag from, or an *InputError if a tag is invalid.
+func (e *ExpenseList) RenameTag(from, to string) (ExpenseList, error) {
+	tags, err := normalizeTags([]string{from, to})
+	if err != nil {
+		return nil, err
+	}
+	from, to = strings.ToLower(strings.TrimSpace(from)), strings.ToLower(strings.TrimSpace(to))
+	if len(tags) == 1 {
+		return nil, inputErrorf("invalid tag %q: the new tag is the same as the old one", to)
+	}
+
+	var changed ExpenseList
+	for index, item := range *e {
+		if !slices.Contains(item.Tags, from) {
+			continue
+		}
+
+		// Build a new slice so that copies of the expense, such as the ones
+		// kept by a HistoryStore, keep their tags.
+		renamed := slices.Clone(item.Tags)
+		renamed[slices.Index(renamed, from)] = to
+		slices.Sort(renamed)
+		(*e)[index].Tags = slices.Compact(renamed)
+		changed = append(changed, (*e)[index])
+	}
+
+	if len(changed) == 0 {
+		return nil, notFoundf("tag not found: no expense is tagged %s", from)
+	}
+	return changed, nil
+}
+
+// TotalsByTag returns the total of every tag, sorted alphabetically, and the
+// grand total. An expense counts toward each of its tags, so the totals of the
+// tags can add up to more than the grand total. Expenses without tags are
+// grouped as "untagged".
+func (e *ExpenseList) TotalsByTag() Totals {
+	tags := make(map[string]Money)
+	totals := e.Totals()
+
+	for _, item := range e.spending() {
+		if len(item.Tags) == 0 {
+			tags[untagged] += item.Amount
+		}
+		for _, tag := range item.Tags {
+			tags[tag] += item.Amount
+		}
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(tags)) {
+		totals.Groups = append(totals.Groups, Group{Name: tag, Total: tags[tag]})
+	}
+	return totals
+}
+
+// SummaryByTag writes the total expenses of every tag to the provided io.Writer
+// in a tabular format, followed by the grand total. Tags are sorted
+// alphabetically, expenses without tags are reported as "untagged" and an
+// expense with several tags counts toward each of them.
+func (e *ExpenseList) SummaryByTag(w io.Writer) {
+	e.TotalsByTag().WriteTable(w, "Tag")
+}
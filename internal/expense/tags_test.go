@@ -0,0 +1,116 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+// newTaggedList returns an ExpenseList with a client expense tagged billable,
+// a billable expense and an expense without tags.
+func newTaggedList(t *testing.T) expense.ExpenseList {
+	t.Helper()
+
+	var expenseList expense.ExpenseList
+	expenseList.Add("Flight", 300_00, "travel", "", time.Time{})
+	expenseList.Add("Lunch", 20_00, "food", "", time.Time{})
+	expenseList.Add("Groceries", 50_00, "food", "", time.Time{})
+
+	if err := expenseList.SetTags(1, []string{"Client-Acme", "billable", "billable"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.SetTags(2, []string{"billable"}); err != nil {
+		t.Fatal(err)
+	}
+	return expenseList
+}
+
+func TestSetTags(t *testing.T) {
+	expenseList := newTaggedList(t)
+
+	item, _ := expenseList.Get(1)
+	expected := []string{"billable", "client-acme"}
+	if !slices.Equal(item.Tags, expected) {
+		t.Errorf("expected %q, but got %q instead", expected, item.Tags)
+	}
+
+	var input *expense.InputError
+	if err := expenseList.SetTags(1, []string{"tax deductible"}); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for a tag with a space, but got %v instead", err)
+	}
+	var notFound *expense.NotFoundError
+	if err := expenseList.SetTags(9, []string{"billable"}); !errors.As(err, &notFound) {
+		t.Errorf("expected a *NotFoundError, but got %v instead", err)
+	}
+}
+
+func TestTagged(t *testing.T) {
+	expenseList := newTaggedList(t)
+
+	tagged, err := expenseList.Tagged([]string{"billable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ids(tagged); !slices.Equal(ids, []int{1, 2}) {
+		t.Errorf("expected the expenses %v, but got %v instead", []int{1, 2}, ids)
+	}
+
+	tagged, err = expenseList.Tagged([]string{"BILLABLE", "client-acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ids(tagged); !slices.Equal(ids, []int{1}) {
+		t.Errorf("expected the expenses %v, but got %v instead", []int{1}, ids)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	expenseList := newTaggedList(t)
+	before, _ := expenseList.Get(1)
+
+	changed, err := expenseList.RenameTag("billable", "client-acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ids(changed); !slices.Equal(ids, []int{1, 2}) {
+		t.Errorf("expected the expenses %v to change, but got %v instead", []int{1, 2}, ids)
+	}
+
+	item, _ := expenseList.Get(1)
+	if !slices.Equal(item.Tags, []string{"client-acme"}) {
+		t.Errorf("expected the merged tag, but got %q instead", item.Tags)
+	}
+	if !slices.Equal(before.Tags, []string{"billable", "client-acme"}) {
+		t.Errorf("expected copies of the expense to keep their tags, but got %q instead", before.Tags)
+	}
+
+	if _, err := expenseList.RenameTag("billable", "invoiced"); err == nil {
+		t.Error("expected an error renaming a tag no expense has")
+	}
+	if _, err := expenseList.RenameTag("client-acme", "Client-Acme"); err == nil {
+		t.Error("expected an error renaming a tag to itself")
+	}
+}
+
+func TestSummaryByTag(t *testing.T) {
+	expenseList := newTaggedList(t)
+
+	var expectedBuf bytes.Buffer
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Tag", "Total"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "billable", "$320.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "client-acme", "$300.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "untagged", "$50.00"))
+	expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$370.00"))
+
+	var gotBuf bytes.Buffer
+	expenseList.SummaryByTag(&gotBuf)
+
+	if expectedBuf.String() != gotBuf.String() {
+		t.Errorf("expected %q\n, but got %q instead", expectedBuf.String(), gotBuf.String())
+	}
+}
@@ -0,0 +1,94 @@
+package expense
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Trashed returns the expense in the trash with the specified ID.
+// If no expense in the trash has the ID, it returns a *NotFoundError.
+func (e *ExpenseList) Trashed(id int) (expense, error) {
+	index, err := e.trashIndexOf(id)
+	if err != nil {
+		return expense{}, err
+	}
+	return (*e)[index], nil
+}
+
+// Restore moves the expense with the specified ID out of the trash.
+// If no expense in the trash has the ID, it returns a *NotFoundError. A refund
+// can only be restored along with its expense: it returns an *InputError if
+// the expense it refunds is in the trash or was removed for good, or if
+// restoring the refund would refund the expense for more than it cost. A
+// restored refund takes the date and category of its expense again.
+func (e *ExpenseList) Restore(id int) error {
+	index, err := e.trashIndexOf(id)
+	if err != nil {
+		return err
+	}
+
+	if item := (*e)[index]; item.refund() {
+		if _, err := e.Trashed(item.RefundOf); err == nil {
+			return inputErrorf("refund %d cannot be restored while expense %d is in the trash: restore the expense first", id, item.RefundOf)
+		}
+		original, err := e.Get(item.RefundOf)
+		if err != nil {
+			return inputErrorf("refund %d cannot be restored: expense %d was removed for good", id, item.RefundOf)
+		}
+		if left := original.Amount - e.refunded(item.RefundOf); item.Amount > left {
+			return inputErrorf("refund of %s exceeds the %s left to refund on expense %d",
+				formatAmount(item.Amount, item.currencyCode()), formatAmount(left, item.currencyCode()), item.RefundOf)
+		}
+		e.syncRefund(index, original)
+	}
+
+	(*e)[index].DeletedAt = time.Time{}
+	return nil
+}
+
+// EmptyTrash removes the expenses that were moved to the trash before the
+// given time from the ExpenseList for good, and returns their IDs. A zero
+// time removes every expense in the trash.
+func (e *ExpenseList) EmptyTrash(before time.Time) []int {
+	var removed []int
+	*e = e.filter(func(item expense) bool {
+		if item.deleted() && (before.IsZero() || item.DeletedAt.Before(before)) {
+			removed = append(removed, item.ID)
+			return false
+		}
+		return true
+	})
+	return removed
+}
+
+// ListTrash writes the expenses in the trash to the provided io.Writer in a
+// tabular format, along with the date each was deleted.
+func (e *ExpenseList) ListTrash(w io.Writer) {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%-6s%-14s%-70s%-20s%-16s%s\n", "ID", "Date", "Description", "Category", "Amount", "Deleted"))
+
+	for _, item := range *e {
+		if !item.deleted() {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf("%-6d%-14s%-70s%-20s%-16s%s\n", item.ID, item.Date.Format("2006-01-02"), item.Description,
+			item.categoryName(), item.formattedAmount(), item.DeletedAt.Format("2006-01-02")))
+	}
+
+	w.Write(buf.Bytes())
+}
+
+// trashIndexOf returns the position in the ExpenseList of the expense with the
+// specified ID, or a *NotFoundError if no expense in the trash has the ID.
+func (e *ExpenseList) trashIndexOf(id int) (int, error) {
+	index, err := e.indexOf(id)
+	if err != nil {
+		return 0, err
+	}
+	if !(*e)[index].deleted() {
+		return 0, &NotFoundError{ID: id}
+	}
+	return index, nil
+}
@@ -0,0 +1,130 @@
+package expense_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestTrash(t *testing.T) {
+	var expenseList expense.ExpenseList
+	expenseList.Add("Demo Expense 1", 100_00, "", "", time.Time{})
+	expenseList.Add("Demo Expense 2", 150_00, "", "", time.Time{})
+	expenseList.Add("Demo Expense 3", 200_00, "", "", time.Time{})
+
+	if err := expenseList.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the trash lists the deleted expenses only.
+	var buf bytes.Buffer
+	expenseList.ListTrash(&buf)
+	today := time.Now().Format("2006-01-02")
+	expected := fmt.Sprintf("%-6s%-14s%-70s%-20s%-16s%s\n", "ID", "Date", "Description", "Category", "Amount", "Deleted") +
+		fmt.Sprintf("%-6d%-14s%-70s%-20s%-16s%s\n", 1, today, "demo expense 1", "uncategorized", "$100.00", today) +
+		fmt.Sprintf("%-6d%-14s%-70s%-20s%-16s%s\n", 2, today, "demo expense 2", "uncategorized", "$150.00", today)
+	if buf.String() != expected {
+		t.Errorf("expected %q, but got %q instead", expected, buf.String())
+	}
+
+	// Assert a restored expense is visible again.
+	if err := expenseList.Restore(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expenseList.Get(1); err != nil {
+		t.Errorf("expected the restored expense, but got %v instead", err)
+	}
+
+	var notFound *expense.NotFoundError
+	if err := expenseList.Restore(3); !errors.As(err, &notFound) {
+		t.Errorf("expected a not found error for an expense outside the trash, but got %v instead", err)
+	}
+
+	// Assert only the expenses deleted before the cutoff are removed.
+	if removed := expenseList.EmptyTrash(time.Now().Add(-time.Hour)); len(removed) != 0 {
+		t.Errorf("expected no expenses removed, but got %v instead", removed)
+	}
+	if removed := expenseList.EmptyTrash(time.Time{}); !slices.Equal(removed, []int{2}) {
+		t.Errorf("expected %v removed, but got %v instead", []int{2}, removed)
+	}
+	if len(expenseList) != 2 {
+		t.Errorf("expected length of the expense list: %d, but got %d instead", 2, len(expenseList))
+	}
+}
+
+func TestRestoreRefund(t *testing.T) {
+	var expenseList expense.ExpenseList
+	expenseList.Add("Supermarket", 120_00, "food", "", time.Time{})
+	if err := expenseList.Refund(1, 40_00, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trash the refund and then its expense, which only has no refunds left.
+	if err := expenseList.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the refund cannot be restored without its expense.
+	var input *expense.InputError
+	if err := expenseList.Restore(2); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError restoring a refund of a trashed expense, but got %v instead", err)
+	}
+
+	if err := expenseList.Restore(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Refund(1, 100_00, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the refund cannot be restored once the expense was refunded in full.
+	if err := expenseList.Restore(2); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError restoring a refund beyond the expense, but got %v instead", err)
+	}
+}
+
+func TestRestoreRefundFollowsExpense(t *testing.T) {
+	var expenseList expense.ExpenseList
+	expenseList.Add("Shirt", 100_00, "clothes", "", time.Date(2026, time.January, 5, 0, 0, 0, 0, time.Local))
+	if err := expenseList.Refund(1, 30_00, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := expenseList.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the expense while its refund is in the trash.
+	date := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.Local)
+	if err := expenseList.Update(1, "", -1, "shoes", date); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the restored refund takes the new date and category of its expense.
+	if err := expenseList.Restore(2); err != nil {
+		t.Fatal(err)
+	}
+	refund, err := expenseList.Get(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Category != "shoes" || !refund.Date.Equal(date) {
+		t.Errorf("expected the refund in shoes on %s, but got %q on %s instead", date.Format("2006-01-02"),
+			refund.Category, refund.Date.Format("2006-01-02"))
+	}
+
+	expected := "[{shoes 70.00}]"
+	if got := fmt.Sprint(expenseList.TotalsByCategory().Groups); got != expected {
+		t.Errorf("expected %q, but got %q instead", expected, got)
+	}
+}
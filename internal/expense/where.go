@@ -0,0 +1,75 @@
+package expense
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hayohtee/expense-tracker/internal/filter"
+)
+
+// fields are the fields of an expense that filter expressions and Sort refer to.
+var fields = filter.Fields{
+	"id":          filter.Number,
+	"date":        filter.Date,
+	"description": filter.String,
+	"category":    filter.String,
+	"amount":      filter.Number,
+	"currency":    filter.String,
+	"tags":        filter.String,
+	"account":     filter.String,
+	"kind":        filter.String,
+}
+
+// Value returns the value of the named field of the expense, as used by
+// filter expressions. Amounts are returned in units, such as dollars, rather than
+// cents, and tags are joined with commas so that `tags ~ billable` finds a tag.
+func (e expense) Value(field string) any {
+	switch field {
+	case "id":
+		return float64(e.ID)
+	case "date":
+		return e.Date
+	case "description":
+		return e.Description
+	case "category":
+		return e.categoryName()
+	case "amount":
+		return float64(e.Amount) / centsPerUnit
+	case "currency":
+		return e.currencyCode()
+	case "tags":
+		return strings.Join(e.Tags, ",")
+	case "account":
+		return e.accountName()
+	case "kind":
+		return e.kindName()
+	default:
+		return nil
+	}
+}
+
+// Where returns a new ExpenseList with the expenses outside the trash that
+// match the filter expression, such as `amount > 50 and category = "food"`.
+// An empty expression matches every expense. It returns an *InputError if the
+// expression is invalid.
+func (e *ExpenseList) Where(expr string) (ExpenseList, error) {
+	parsed, err := filter.Parse(expr, fields)
+	if err != nil {
+		return nil, inputErrorf("%v", err)
+	}
+	return e.filter(func(item expense) bool { return !item.deleted() && parsed.Match(item) }), nil
+}
+
+// Sort sorts the ExpenseList by the named field, such as amount or date, in
+// ascending order. Expenses with equal values keep their order. It returns an
+// *InputError if the expenses have no such field.
+func (e *ExpenseList) Sort(field string) error {
+	field = strings.ToLower(field)
+	if _, ok := fields[field]; !ok {
+		return inputErrorf("invalid sort field %q: expected one of %s", field, strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+	}
+
+	slices.SortStableFunc(*e, func(x, y expense) int { return filter.Compare(x.Value(field), y.Value(field)) })
+	return nil
+}
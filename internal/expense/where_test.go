@@ -0,0 +1,83 @@
+package expense_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
+)
+
+func TestWhere(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	date := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	expenseList.Add("Uber to airport", 62_00, "food", "", date)
+	expenseList.Add("Lunch", 12_50, "food", "", date)
+	expenseList.Add("Uber eats", 55_00, "travel", "", date.AddDate(0, 1, 0))
+	expenseList.Add("Uber home", 70_00, "food", "", date)
+	expenseList.Delete(4)
+
+	matched, err := expenseList.Where(`amount > 50 and category = "food" and description ~ "uber"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ids(matched); !slices.Equal(ids, []int{1}) {
+		t.Errorf("expected the expenses %v, but got %v instead", []int{1}, ids)
+	}
+
+	matched, err = expenseList.Where("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := ids(matched); !slices.Equal(ids, []int{1, 2, 3}) {
+		t.Errorf("expected the expenses %v, but got %v instead", []int{1, 2, 3}, ids)
+	}
+
+	var input *expense.InputError
+	if _, err := expenseList.Where("amount >"); !errors.As(err, &input) {
+		t.Errorf("expected an *InputError for an invalid filter, but got %v instead", err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	var expenseList expense.ExpenseList
+
+	date := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.Local)
+	expenseList.Add("Taxi", 15_00, "travel", "", date.AddDate(0, 0, 2))
+	expenseList.Add("Lunch", 20_00, "food", "", date)
+	expenseList.Add("Dinner", 15_00, "food", "", date.AddDate(0, 0, 1))
+
+	testCases := []struct {
+		field    string
+		expected []int
+	}{
+		{field: "amount", expected: []int{1, 3, 2}},
+		{field: "date", expected: []int{2, 3, 1}},
+		{field: "Description", expected: []int{3, 2, 1}},
+		{field: "id", expected: []int{1, 2, 3}},
+	}
+
+	for _, tc := range testCases {
+		if err := expenseList.Sort(tc.field); err != nil {
+			t.Fatal(err)
+		}
+		if ids := ids(expenseList); !slices.Equal(ids, tc.expected) {
+			t.Errorf("expected sorting by %s to give %v, but got %v instead", tc.field, tc.expected, ids)
+		}
+	}
+
+	if err := expenseList.Sort("size"); err == nil {
+		t.Error("expected an error sorting by an unknown field")
+	}
+}
+
+// ids returns the IDs of the expenses in the list, in order.
+func ids(e expense.ExpenseList) []int {
+	var ids []int
+	for index := range e {
+		ids = append(ids, e[index].ID)
+	}
+	return ids
+}
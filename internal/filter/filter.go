@@ -0,0 +1,310 @@
+// Package filter parses and evaluates filter expressions such as
+// `amount > 50 and category = "food" and description ~ "uber"`.
+//
+// An expression compares fields with literal values and combines the comparisons
+// with and, or, not and parentheses. The comparison operators are =, !=, <, <=,
+// >, >=, ~ (contains) and !~ (does not contain). Strings are compared without
+// regard to case, and literals are quoted with double or single quotes unless
+// they are a single word, such as 50 or 2024-08-06.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of the values of a field.
+type Kind int
+
+// Kinds of the values of a field. A Record returns a string for a String field,
+// a float64 for a Number field and a time.Time for a Date field.
+const (
+	String Kind = iota
+	Number
+	Date
+)
+
+// String returns the name of the kind, such as "number".
+func (k Kind) String() string {
+	switch k {
+	case Number:
+		return "number"
+	case Date:
+		return "date"
+	default:
+		return "string"
+	}
+}
+
+// Fields maps the names of the fields an expression can refer to to their kind.
+type Fields map[string]Kind
+
+// Record is an item an expression is evaluated against.
+type Record interface {
+	// Value returns the value of the named field, of the type of its Kind.
+	Value(field string) any
+}
+
+// SyntaxError is returned when an expression cannot be parsed.
+type SyntaxError struct {
+	Pos int    // Position in the expression, from 1, where the error was found
+	Msg string // Description of the error
+}
+
+// Error returns the message of the SyntaxError.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Pos, e.Msg)
+}
+
+// Expr is a parsed filter expression.
+type Expr struct {
+	root node
+}
+
+// Parse parses the expression, checking that it only refers to the given fields
+// and compares them with values of their kind. An empty expression matches
+// every record. It returns a *SyntaxError if the expression is invalid.
+func Parse(input string, fields Fields) (*Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, fields: fields}
+	if p.peek().kind == tokenEnd {
+		return &Expr{}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEnd {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return &Expr{root: root}, nil
+}
+
+// Match reports whether the record matches the expression.
+func (e *Expr) Match(r Record) bool {
+	if e.root == nil {
+		return true
+	}
+	return e.root.eval(r)
+}
+
+// Compare returns -1, 0 or +1 depending on whether the value a is less than,
+// equal to or greater than the value b. Both values must be of the same kind:
+// strings, which are compared without regard to case, float64 numbers or times.
+func Compare(a, b any) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return strings.Compare(strings.ToLower(a.(string)), strings.ToLower(b.(string)))
+	}
+}
+
+// node is a node of the tree of a parsed expression.
+type node interface {
+	eval(r Record) bool
+}
+
+// and matches the records that match both of its operands.
+type and struct{ left, right node }
+
+func (n and) eval(r Record) bool { return n.left.eval(r) && n.right.eval(r) }
+
+// or matches the records that match either of its operands.
+type or struct{ left, right node }
+
+func (n or) eval(r Record) bool { return n.left.eval(r) || n.right.eval(r) }
+
+// not matches the records that do not match its operand.
+type not struct{ operand node }
+
+func (n not) eval(r Record) bool { return !n.operand.eval(r) }
+
+// comparison matches the records whose field compares with the value as the
+// operator requires. The value is of the kind of the field.
+type comparison struct {
+	field string
+	kind  Kind
+	op    string
+	value any
+}
+
+func (n comparison) eval(r Record) bool {
+	value := r.Value(n.field)
+	if n.kind == Date {
+		value = day(value.(time.Time))
+	}
+
+	switch n.op {
+	case "~":
+		return strings.Contains(strings.ToLower(value.(string)), strings.ToLower(n.value.(string)))
+	case "!~":
+		return !strings.Contains(strings.ToLower(value.(string)), strings.ToLower(n.value.(string)))
+	}
+
+	c := Compare(value, n.value)
+	switch n.op {
+	case "=", "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// day returns the start of the day of the time in the local time zone, so that
+// dates are compared by day whatever the time they were recorded at.
+func day(t time.Time) time.Time {
+	year, month, d := t.Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+}
+
+// parser builds the tree of an expression from its tokens by recursive descent.
+type parser struct {
+	tokens []token
+	next   int
+	fields Fields
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// take consumes and returns the next token.
+func (p *parser) take() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEnd {
+		p.next++
+	}
+	return tok
+}
+
+// parseOr parses one or more operands separated by "or".
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.take()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses one or more operands separated by "and".
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") {
+		p.take()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negated operand, a parenthesized expression or a comparison.
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	switch {
+	case tok.isKeyword("not"):
+		p.take()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{operand}, nil
+	case tok.kind == tokenOpen:
+		p.take()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.take(); closing.kind != tokenClose {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: "expected )"}
+		}
+		return inner, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+// parseComparison parses a field, an operator and a value of the kind of the field.
+func (p *parser) parseComparison() (node, error) {
+	name := p.take()
+	if name.kind != tokenWord {
+		return nil, &SyntaxError{Pos: name.pos, Msg: "expected a field"}
+	}
+	field := strings.ToLower(name.text)
+	kind, ok := p.fields[field]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown field %q", name.text)}
+	}
+
+	op := p.take()
+	if op.kind != tokenOperator {
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("expected an operator after %s", field)}
+	}
+	if (op.text == "~" || op.text == "!~") && kind != String {
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("%s cannot be used with the %s field %s", op.text, kind, field)}
+	}
+
+	literal := p.take()
+	if literal.kind != tokenWord && literal.kind != tokenString {
+		return nil, &SyntaxError{Pos: literal.pos, Msg: fmt.Sprintf("expected a value after %s", op.text)}
+	}
+
+	var value any
+	switch kind {
+	case Number:
+		number, err := strconv.ParseFloat(literal.text, 64)
+		if err != nil {
+			return nil, &SyntaxError{Pos: literal.pos, Msg: fmt.Sprintf("%s is compared with %q, expected a number", field, literal.text)}
+		}
+		value = number
+	case Date:
+		date, err := time.ParseInLocation("2006-01-02", literal.text, time.Local)
+		if err != nil {
+			return nil, &SyntaxError{Pos: literal.pos, Msg: fmt.Sprintf("%s is compared with %q, expected YYYY-MM-DD", field, literal.text)}
+		}
+		value = date
+	default:
+		value = literal.text
+	}
+
+	return comparison{field: field, kind: kind, op: op.text, value: value}, nil
+}
@@ -0,0 +1,101 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/filter"
+)
+
+// record is a filter.Record backed by a map of field values.
+type record map[string]any
+
+func (r record) Value(field string) any {
+	return r[field]
+}
+
+var fields = filter.Fields{"name": filter.String, "price": filter.Number, "day": filter.Date}
+
+func TestMatch(t *testing.T) {
+	item := record{"name": "Uber to airport", "price": 62.0, "day": time.Date(2024, time.August, 6, 18, 30, 0, 0, time.Local)}
+
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: "", expected: true},
+		{expr: "price > 50", expected: true},
+		{expr: "price>=62 and price<=62", expected: true},
+		{expr: "price != 62", expected: false},
+		{expr: `name = "uber to airport"`, expected: true},
+		{expr: `name ~ "UBER"`, expected: true},
+		{expr: `name !~ 'uber'`, expected: false},
+		{expr: "day = 2024-08-06", expected: true},
+		{expr: "day < 2024-08-06", expected: false},
+		{expr: `price > 100 or name ~ airport`, expected: true},
+		{expr: `not (price > 100 or name ~ airport)`, expected: false},
+		{expr: `price > 50 AND NOT name ~ taxi`, expected: true},
+		{expr: `price > 100 and name ~ uber or day > 2024-01-01`, expected: true},
+	}
+
+	for _, tc := range testCases {
+		expr, err := filter.Parse(tc.expr, fields)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", tc.expr, err)
+			continue
+		}
+		if got := expr.Match(item); got != tc.expected {
+			t.Errorf("expected %q to be %t, but got %t instead", tc.expr, tc.expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		pos  int
+	}{
+		{expr: "size > 5", pos: 1},
+		{expr: "price > ", pos: 9},
+		{expr: "price ~ 5", pos: 7},
+		{expr: "price > ten", pos: 9},
+		{expr: "day = yesterday", pos: 7},
+		{expr: `name = "uber`, pos: 8},
+		{expr: "(price > 5", pos: 11},
+		{expr: "price > 5 price < 9", pos: 11},
+		{expr: "price # 5", pos: 7},
+	}
+
+	for _, tc := range testCases {
+		_, err := filter.Parse(tc.expr, fields)
+		var syntaxErr *filter.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("expected a *SyntaxError parsing %q, but got %v instead", tc.expr, err)
+			continue
+		}
+		if syntaxErr.Pos != tc.pos {
+			t.Errorf("expected the error in %q at position %d, but got %d instead (%v)", tc.expr, tc.pos, syntaxErr.Pos, err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		a, b     any
+		expected int
+	}{
+		{a: 1.5, b: 2.0, expected: -1},
+		{a: 2.0, b: 2.0, expected: 0},
+		{a: "Food", b: "food", expected: 0},
+		{a: "travel", b: "food", expected: 1},
+		{a: now, b: now.Add(time.Hour), expected: -1},
+	}
+
+	for _, tc := range testCases {
+		if got := filter.Compare(tc.a, tc.b); got != tc.expected {
+			t.Errorf("expected comparing %v and %v to give %d, but got %d instead", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
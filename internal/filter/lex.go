@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a token of an expression.
+type tokenKind int
+
+// Kinds of the tokens of an expression.
+const (
+	tokenEnd      tokenKind = iota // End of the expression
+	tokenWord                      // Field, keyword or unquoted value, such as amount, and or 50
+	tokenString                    // Quoted value, without its quotes
+	tokenOperator                  // Comparison operator, such as >=
+	tokenOpen                      // Opening parenthesis
+	tokenClose                     // Closing parenthesis
+)
+
+// token is a token of an expression.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // Position of the token in the expression, from 1
+}
+
+// isKeyword reports whether the token is the given keyword, in any case.
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// operators are the comparison operators, with the longer ones first so that
+// they are preferred over their prefixes.
+var operators = []string{"==", "!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+// lex splits the expression into tokens, ending with a tokenEnd token.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: pos})
+			i++
+		case r == '"' || r == '\'':
+			var text strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &SyntaxError{Pos: pos, Msg: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), pos: pos})
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: pos})
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, text: "end of filter", pos: len(runes) + 1}), nil
+}
+
+// isWordRune reports whether the rune can be part of a word, such as a field
+// name, a number or a date.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
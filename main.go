@@ -1,129 +1,1328 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hayohtee/expense-tracker/internal/expense"
 )
 
-const filename = ".expense_list.json"
+// fileEnv is the environment variable that names the file to keep the expenses in.
+const fileEnv = "EXPENSE_TRACKER_FILE"
+
+// legacyFile is the file in the working directory that versions before named
+// ledgers kept the expenses in.
+const legacyFile = ".expense_list.json"
+
+// Formats the results of the commands can be written in.
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+)
+
+// lockTimeout is how long a command waits for another command to release the ledger.
+const lockTimeout = 5 * time.Second
+
+// commands maps the name of every command to whether it can write its results
+// in jsonOutput. The other commands only write text.
+var commands = map[string]bool{
+	"add":       true,
+	"income":    true,
+	"list":      true,
+	"search":    true,
+	"summary":   true,
+	"delete":    true,
+	"update":    true,
+	"split":     true,
+	"refund":    true,
+	"balance":   true,
+	"budget":    false,
+	"rates":     false,
+	"recurring": false,
+	"export":    false,
+	"import":    false,
+	"trash":     false,
+	"history":   false,
+	"undo":      false,
+	"redo":      false,
+	"migrate":   false,
+	"compact":   false,
+	"account":   false,
+	"tag":       false,
+	"ledger":    false,
+}
 
 func main() {
-	addCmd := flag.NewFlagSet("add", flag.ExitOnError)
-	listCmd := flag.NewFlagSet("list", flag.ExitOnError)
-	summaryCmd := flag.NewFlagSet("summary", flag.ExitOnError)
-	deleteCmd := flag.NewFlagSet("delete", flag.ExitOnError)
-	updateCmd := flag.NewFlagSet("update", flag.ExitOnError)
+	globalFlags := flag.NewFlagSet("expense-tracker", flag.ContinueOnError)
+	addCmd := flag.NewFlagSet("add", flag.ContinueOnError)
+	incomeCmd := flag.NewFlagSet("income", flag.ContinueOnError)
+	listCmd := flag.NewFlagSet("list", flag.ContinueOnError)
+	searchCmd := flag.NewFlagSet("search", flag.ContinueOnError)
+	summaryCmd := flag.NewFlagSet("summary", flag.ContinueOnError)
+	deleteCmd := flag.NewFlagSet("delete", flag.ContinueOnError)
+	updateCmd := flag.NewFlagSet("update", flag.ContinueOnError)
+	refundCmd := flag.NewFlagSet("refund", flag.ContinueOnError)
+	splitCmd := flag.NewFlagSet("split", flag.ContinueOnError)
+	budgetSetCmd := flag.NewFlagSet("budget set", flag.ContinueOnError)
+	budgetListCmd := flag.NewFlagSet("budget list", flag.ContinueOnError)
+	budgetRemoveCmd := flag.NewFlagSet("budget remove", flag.ContinueOnError)
+	rateSetCmd := flag.NewFlagSet("rates set", flag.ContinueOnError)
+	rateListCmd := flag.NewFlagSet("rates list", flag.ContinueOnError)
+	rateRemoveCmd := flag.NewFlagSet("rates remove", flag.ContinueOnError)
+	recurringAddCmd := flag.NewFlagSet("recurring add", flag.ContinueOnError)
+	recurringListCmd := flag.NewFlagSet("recurring list", flag.ContinueOnError)
+	recurringPauseCmd := flag.NewFlagSet("recurring pause", flag.ContinueOnError)
+	recurringResumeCmd := flag.NewFlagSet("recurring resume", flag.ContinueOnError)
+	recurringDeleteCmd := flag.NewFlagSet("recurring delete", flag.ContinueOnError)
+	recurringMaterializeCmd := flag.NewFlagSet("recurring materialize", flag.ContinueOnError)
+	exportCmd := flag.NewFlagSet("export", flag.ContinueOnError)
+	importCmd := flag.NewFlagSet("import", flag.ContinueOnError)
+	ledgerCreateCmd := flag.NewFlagSet("ledger create", flag.ContinueOnError)
+	ledgerSwitchCmd := flag.NewFlagSet("ledger switch", flag.ContinueOnError)
+	ledgerListCmd := flag.NewFlagSet("ledger list", flag.ContinueOnError)
+	migrateCmd := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	compactCmd := flag.NewFlagSet("compact", flag.ContinueOnError)
+	historyCmd := flag.NewFlagSet("history", flag.ContinueOnError)
+	trashListCmd := flag.NewFlagSet("trash list", flag.ContinueOnError)
+	trashRestoreCmd := flag.NewFlagSet("trash restore", flag.ContinueOnError)
+	trashEmptyCmd := flag.NewFlagSet("trash empty", flag.ContinueOnError)
+	undoCmd := flag.NewFlagSet("undo", flag.ContinueOnError)
+	redoCmd := flag.NewFlagSet("redo", flag.ContinueOnError)
+	tagRenameCmd := flag.NewFlagSet("tag rename", flag.ContinueOnError)
+	accountAddCmd := flag.NewFlagSet("account add", flag.ContinueOnError)
+	accountListCmd := flag.NewFlagSet("account list", flag.ContinueOnError)
+	accountRemoveCmd := flag.NewFlagSet("account remove", flag.ContinueOnError)
+	balanceCmd := flag.NewFlagSet("balance", flag.ContinueOnError)
+
+	file := globalFlags.String("file", "", "The file to keep the expenses in (overrides "+fileEnv+" and the selected ledger)")
+	output := globalFlags.String("output", textOutput, "The format to write the results of list, search, summary, balance, add, income, refund, split, update and delete in (text or json)")
 
 	description := addCmd.String("description", "", "The description for the expense")
-	amount := addCmd.Float64("amount", 0, "The amount for the expense")
+	var amount expense.Money
+	addCmd.Var(&amount, "amount", "The amount for the expense")
+	category := addCmd.String("category", "", "The category for the expense")
+	currency := addCmd.String("currency", expense.DefaultCurrency, "The currency of the amount")
+	date := addCmd.String("date", "", "The date the expense was incurred as YYYY-MM-DD (today if empty)")
+	var addTags repeatedFlag
+	addCmd.Var(&addTags, "tag", "A tag for the expense (can be repeated)")
+	addAccount := addCmd.String("account", "", "The payment account the expense was paid from")
+	incomeDescription := incomeCmd.String("description", "", "The description for the income")
+	var incomeAmount expense.Money
+	incomeCmd.Var(&incomeAmount, "amount", "The amount of the income")
+	incomeCategory := incomeCmd.String("category", "", "The category for the income, such as salary")
+	incomeCurrency := incomeCmd.String("currency", expense.DefaultCurrency, "The currency of the amount")
+	incomeDate := incomeCmd.String("date", "", "The date the income was received as YYYY-MM-DD (today if empty)")
+	incomeAccount := incomeCmd.String("account", "", "The payment account the income was paid into")
 	newDescription := updateCmd.String("description", "", "The new description for the expense")
-	newAmount := updateCmd.Float64("amount", 0, "the new amount for the expense")
+	newAmount := updateCmd.String("amount", "", "the new amount for the expense")
+	newCategory := updateCmd.String("category", "", "The new category for the expense")
+	newDate := updateCmd.String("date", "", "The new date the expense was incurred as YYYY-MM-DD")
 	newID := updateCmd.Int("id", 0, "The ID of the expense to update")
+	var updateTags repeatedFlag
+	updateCmd.Var(&updateTags, "tag", "A new tag for the expense, replacing its tags (can be repeated)")
+	updateAccount := updateCmd.String("account", "", "The new payment account the expense was paid from (none if empty)")
+	refundID := refundCmd.Int("id", 0, "The ID of the expense that was refunded")
+	var refundAmount expense.Money
+	refundCmd.Var(&refundAmount, "amount", "The amount refunded, up to what is left of the expense")
+	splitID := splitCmd.Int("id", 0, "The ID of the expense to split")
+	var splitParts repeatedFlag
+	splitCmd.Var(&splitParts, "part", "A part of the expense as category=amount, such as groceries=90 (can be repeated)")
+	splitClear := splitCmd.Bool("clear", false, "Remove the split of the expense")
+	refundDescription := refundCmd.String("description", "", `The description for the refund ("refund: " and the description of the expense if empty)`)
 	month := summaryCmd.Int("month", 0, "The month to generate the summary for")
+	year := summaryCmd.Int("year", time.Now().Year(), "The year to generate the summary for")
+	monthly := summaryCmd.Bool("monthly", false, "Break the summary for the year down by month")
+	groupBy := summaryCmd.String("by", "", "Group the summary by the given field (category, tag or account)")
+	reportCurrency := summaryCmd.String("report-currency", expense.DefaultCurrency, "The currency to report the summary in")
+	summaryFrom := summaryCmd.String("from", "", "Only summarize expenses on or after this date (YYYY-MM-DD)")
+	summaryTo := summaryCmd.String("to", "", "Only summarize expenses on or before this date (YYYY-MM-DD)")
+	listFrom := listCmd.String("from", "", "Only list expenses on or after this date (YYYY-MM-DD)")
+	listTo := listCmd.String("to", "", "Only list expenses on or before this date (YYYY-MM-DD)")
+	where := listCmd.String("where", "", `Only list expenses that match this filter, such as 'amount > 50 and category = "food"'`)
+	sortBy := listCmd.String("sort", "", "Sort the expenses by this field (id, date, description, category, amount, currency, tags, account or kind)")
+	limit := listCmd.Int("limit", 0, "List at most this many expenses (all if zero)")
+	reverse := listCmd.Bool("reverse", false, "List the expenses in reverse order")
+	var listTags repeatedFlag
+	listCmd.Var(&listTags, "tag", "Only list expenses with this tag (can be repeated)")
+	searchLimit := searchCmd.Int("limit", 10, "List at most this many matches (all if zero)")
 	id := deleteCmd.Int("id", 0, "The ID of the expense to delete")
+	var budgetAmount expense.Money
+	budgetSetCmd.Var(&budgetAmount, "amount", "The monthly budget amount")
+	budgetCategory := budgetSetCmd.String("category", "", "The category of the budget (overall if empty)")
+	removeCategory := budgetRemoveCmd.String("category", "", "The category of the budget to remove (overall if empty)")
+	rateFrom := rateSetCmd.String("from", "", "The currency to convert from")
+	rateTo := rateSetCmd.String("to", "", "The currency to convert to")
+	rateValue := rateSetCmd.String("rate", "", "The value of one unit of the from currency in the to currency")
+	removeFrom := rateRemoveCmd.String("from", "", "The currency of the rate to remove")
+	removeTo := rateRemoveCmd.String("to", "", "The other currency of the rate to remove")
+	recurringDescription := recurringAddCmd.String("description", "", "The description for the recurring expense")
+	var recurringAmount expense.Money
+	recurringAddCmd.Var(&recurringAmount, "amount", "The amount for the recurring expense")
+	recurringCategory := recurringAddCmd.String("category", "", "The category for the recurring expense")
+	recurringCurrency := recurringAddCmd.String("currency", expense.DefaultCurrency, "The currency of the amount")
+	frequency := recurringAddCmd.String("frequency", expense.Monthly, "How often the expense occurs (daily, weekly, monthly or yearly)")
+	interval := recurringAddCmd.Int("interval", 1, "The number of days, weeks, months or years between occurrences")
+	start := recurringAddCmd.String("start", "", "The date of the first occurrence as YYYY-MM-DD (today if empty)")
+	pauseID := recurringPauseCmd.Int("id", 0, "The ID of the recurring expense to pause")
+	resumeID := recurringResumeCmd.Int("id", 0, "The ID of the recurring expense to resume")
+	recurringID := recurringDeleteCmd.Int("id", 0, "The ID of the recurring expense to delete")
+	exportFormat := exportCmd.String("format", "csv", "The format to export the expenses in (csv)")
+	importFormat := importCmd.String("format", "csv", "The format of the file to import (csv)")
+	dateColumn := importCmd.String("date-column", "date", "The name or 1-based position of the date column")
+	descriptionColumn := importCmd.String("description-column", "description", "The name or 1-based position of the description column")
+	amountColumn := importCmd.String("amount-column", "amount", "The name or 1-based position of the amount column")
+	categoryColumn := importCmd.String("category-column", "", "The name or 1-based position of the category column, if any")
+	currencyColumn := importCmd.String("currency-column", "", "The name or 1-based position of the currency column, if any")
+	kindColumn := importCmd.String("kind-column", "", "The name or 1-based position of the column telling expenses (expense) from income (income), if any")
+	tagsColumn := importCmd.String("tags-column", "", "The name or 1-based position of the column of tags separated by commas, if any")
+	accountColumn := importCmd.String("account-column", "", "The name or 1-based position of the column of the account each expense was paid from, if any")
+	dateFormat := importCmd.String("date-format", "", "The Go time layout of the dates, such as 02/01/2006 (RFC 3339 or YYYY-MM-DD if empty)")
+	importCurrency := importCmd.String("currency", expense.DefaultCurrency, "The currency of rows without a currency column")
+	negate := importCmd.Bool("negate", false, "Flip the sign of the amounts, for statements that list spending as negative")
+	delimiter := importCmd.String("delimiter", ",", "The field delimiter of the file")
+	decimalSeparator := importCmd.String("decimal-separator", ".", "The decimal separator of the amounts (. or ,), the other one being read as a thousands separator")
+	restoreID := trashRestoreCmd.Int("id", 0, "The ID of the deleted expense to restore")
+	olderThan := trashEmptyCmd.String("older-than", "", "Only remove expenses deleted longer ago than this, such as 30d or 12h (all if empty)")
+	historyID := historyCmd.Int("id", 0, "Only show the changes to the expense with this ID")
+	accountName := accountAddCmd.String("name", "", "The name of the account")
+	accountType := accountAddCmd.String("type", expense.Card, "The type of the account (card, cash or bank)")
+	accountCurrency := accountAddCmd.String("currency", expense.DefaultCurrency, "The currency the account is held in")
+	var openingBalance expense.Money
+	accountAddCmd.Var(&openingBalance, "opening-balance", "The balance of the account before any expense")
+	removeAccount := accountRemoveCmd.String("name", "", "The name of the account to remove")
+	migrateTo := migrateCmd.String("to", "", "The SQLite database or journal to move the expenses into (the ledger file with a .db extension if empty)")
+
+	// Write errors as a JSON object with an error code when JSON output is
+	// requested, so that scripts can tell the kinds of failure apart.
+	fail := func(err error) {
+		if *output == jsonOutput {
+			writeJSON(os.Stderr, errorResult{Error: errorDetail{Code: errorCode(err), Message: err.Error()}})
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
 
-	if len(os.Args) < 2 {
-		displayUsage(addCmd, summaryCmd, updateCmd, deleteCmd)
+	// Parse the flags of a command, failing with an *InputError on invalid
+	// flags so that they are reported like any other invalid input. The usage
+	// of the command is only written along with the error in text output.
+	parse := func(flagSet *flag.FlagSet, arguments []string) {
+		flagSet.SetOutput(io.Discard)
+		err := flagSet.Parse(arguments)
+		flagSet.SetOutput(nil)
+		if err == nil {
+			return
+		}
+
+		if *output != jsonOutput {
+			flagSet.Usage()
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fail(&expense.InputError{Message: err.Error()})
+	}
+
+	parse(globalFlags, os.Args[1:])
+	args := globalFlags.Args()
+	if *output != textOutput && *output != jsonOutput {
+		fail(&expense.InputError{Message: fmt.Sprintf("invalid output %q: expected text or json", *output)})
+	}
+
+	if len(args) < 1 {
+		displayUsage(globalFlags, addCmd, incomeCmd, listCmd, searchCmd, summaryCmd, updateCmd, refundCmd, splitCmd, deleteCmd, budgetSetCmd, budgetRemoveCmd, rateSetCmd, rateRemoveCmd,
+			recurringAddCmd, recurringPauseCmd, recurringResumeCmd, recurringDeleteCmd, exportCmd, importCmd,
+			ledgerCreateCmd, ledgerSwitchCmd, migrateCmd, historyCmd, trashRestoreCmd, trashEmptyCmd, tagRenameCmd, accountAddCmd, accountRemoveCmd)
 		os.Exit(0)
 	}
 
-	// Load the expense list from the file.
-	var expenseList expense.ExpenseList
-	if err := expenseList.Load(filename); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Check the command before the ledger is touched, so that an unknown command
+	// neither creates the directory of the ledger nor waits for its lock. JSON
+	// output is refused by the commands that only write text.
+	withJSON, ok := commands[args[0]]
+	if !ok {
+		fail(&expense.InputError{Message: fmt.Sprintf("unknown command %q", args[0])})
+	}
+	if *output == jsonOutput && !withJSON {
+		fail(&expense.InputError{Message: fmt.Sprintf("invalid output %q: %s only writes text", *output, args[0])})
 	}
 
-	switch os.Args[1] {
+	// Find the files of the ledger and create their directory if needed.
+	path, err := ledgerPath(*file)
+	if err != nil {
+		fail(err)
+	}
+	ledger := expense.NewLedger(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fail(err)
+	}
+
+	// Lock the ledger so that concurrent commands cannot overwrite each other's
+	// changes. The lock is released when the process exits.
+	lock, err := expense.AcquireLock(ledger.Expenses, lockTimeout)
+	if err != nil {
+		fail(err)
+	}
+	defer lock.Release()
+
+	// Open the store the expenses are kept in, record every change made to it
+	// so that changes can be undone, and load the expense list from it. The
+	// changes made by the command are undone and redone together.
+	backend, err := expense.OpenStore(ledger.Expenses)
+	if err != nil {
+		fail(err)
+	}
+	defer backend.Close()
+	store := expense.NewHistoryStore(backend, ledger.History)
+	store.Begin()
+
+	expenseList, err := store.Load()
+	if err != nil {
+		fail(err)
+	}
+
+	// Earlier versions kept the expenses in the working directory. Point to
+	// them rather than silently showing an empty default ledger that was never
+	// written to.
+	if *file == "" && os.Getenv(fileEnv) == "" && len(expenseList) == 0 {
+		name, _ := expense.CurrentLedger()
+		_, ledgerErr := os.Stat(path)
+		if _, err := os.Stat(legacyFile); err == nil && name == expense.DefaultLedger && errors.Is(ledgerErr, os.ErrNotExist) {
+			displayWarnings([]string{fmt.Sprintf("the ledger is empty, but %s holds the expenses of an earlier version: "+
+				"open it with --file %s, or move it to %s", legacyFile, legacyFile, path)})
+		}
+	}
+
+	// Load the budget list from the file.
+	var budgetList expense.BudgetList
+	if err := budgetList.Load(ledger.Budgets); err != nil {
+		fail(err)
+	}
+
+	// Load the exchange rates from the file.
+	var rateList expense.RateList
+	if err := rateList.Load(ledger.Rates); err != nil {
+		fail(err)
+	}
+
+	// Load the recurring expense rules from the file.
+	var recurringList expense.RecurringList
+	if err := recurringList.Load(ledger.Recurring); err != nil {
+		fail(err)
+	}
+
+	// Load the payment accounts from the file.
+	var accountList expense.AccountList
+	if err := accountList.Load(ledger.Accounts); err != nil {
+		fail(err)
+	}
+
+	switch args[0] {
 	case "add":
-		if err := addCmd.Parse(os.Args[2:]); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		parse(addCmd, args[1:])
+
+		// Parse the date if one was supplied, a zero date means today.
+		var addDate time.Time
+		if *date != "" {
+			parsed, err := parseDate(*date)
+			if err != nil {
+				fail(err)
+			}
+			addDate = parsed
 		}
 
-		// Add new expense to the list.
-		if err := expenseList.Add(*description, *amount); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		// Add new expense to the list, with an ID no expense has had before and
+		// with its tags if any were supplied.
+		if err := expenseList.Add(*description, amount, *category, *currency, addDate); err != nil {
+			fail(err)
+		}
+		if err := store.Renumber(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
+		}
+		if len(addTags) > 0 {
+			if err := expenseList.SetTags(expenseList[len(expenseList)-1].ID, addTags); err != nil {
+				fail(err)
+			}
+		}
+
+		// Record the account the expense was paid from, which must be defined.
+		if *addAccount != "" {
+			if _, err := accountList.Get(*addAccount); err != nil {
+				fail(err)
+			}
+			if err := expenseList.SetAccount(expenseList[len(expenseList)-1].ID, *addAccount); err != nil {
+				fail(err)
+			}
 		}
 
 		// Write successful message to the STDOUT.
-		fmt.Printf("Expense added successfully (ID: %d)\n", expenseList[len(expenseList)-1].ID)
+		item := expenseList[len(expenseList)-1]
+		if *output == textOutput {
+			fmt.Printf("Expense added successfully (ID: %d)\n", item.ID)
+		}
 
-		// Save the new list.
-		if err := expenseList.Save(filename); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		// Store the new expense.
+		if err := store.Append(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
 		}
+
+		// Warn about any budget the new expense has exceeded.
+		warnings := budgetWarnings(budgetList, expenseList, rateList, item.Date, item.Category)
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record(), Warnings: warnings})
+			return
+		}
+		displayWarnings(warnings)
+	case "income":
+		parse(incomeCmd, args[1:])
+
+		// Parse the date if one was supplied, a zero date means today.
+		var receivedDate time.Time
+		if *incomeDate != "" {
+			parsed, err := parseDate(*incomeDate)
+			if err != nil {
+				fail(err)
+			}
+			receivedDate = parsed
+		}
+
+		// Add the income to the list, with the account it was paid into if any.
+		if err := expenseList.AddIncome(*incomeDescription, incomeAmount, *incomeCategory, *incomeCurrency, receivedDate); err != nil {
+			fail(err)
+		}
+		if err := store.Renumber(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
+		}
+		if *incomeAccount != "" {
+			if _, err := accountList.Get(*incomeAccount); err != nil {
+				fail(err)
+			}
+			if err := expenseList.SetAccount(expenseList[len(expenseList)-1].ID, *incomeAccount); err != nil {
+				fail(err)
+			}
+		}
+
+		// Store the new income.
+		item := expenseList[len(expenseList)-1]
+		if err := store.Append(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
+		}
+
+		// Write successful message to the STDOUT.
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record()})
+			return
+		}
+		fmt.Printf("Income added successfully (ID: %d)\n", item.ID)
 	case "list":
-		if err := listCmd.Parse(os.Args[2:]); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		parse(listCmd, args[1:])
+
+		// Narrow the list down to the supplied date range.
+		listFromDate, listToDate, err := parseDateRange(*listFrom, *listTo)
+		if err != nil {
+			fail(err)
+		}
+		rangeList, err := store.Query(expense.Query{From: listFromDate, To: listToDate})
+		if err != nil {
+			fail(err)
+		}
+
+		// Narrow the list down to the expenses that match the filter, if any.
+		rangeList, err = rangeList.Where(*where)
+		if err != nil {
+			fail(err)
+		}
+		if len(listTags) > 0 {
+			if rangeList, err = rangeList.Tagged(listTags); err != nil {
+				fail(err)
+			}
+		}
+
+		// Order the list by the supplied field and direction, and keep as many
+		// expenses as the limit allows.
+		if *sortBy != "" {
+			if err := rangeList.Sort(*sortBy); err != nil {
+				fail(err)
+			}
 		}
+		if *reverse {
+			slices.Reverse(rangeList)
+		}
+		if *limit < 0 {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid limit %d: limit must not be negative", *limit)})
+		}
+		if *limit > 0 && *limit < len(rangeList) {
+			rangeList = rangeList[:*limit]
+		}
+
 		// Write the list of expense to the STDOUT.
-		expenseList.List(os.Stdout)
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, listResult{Expenses: rangeList.Records()})
+			return
+		}
+		rangeList.List(os.Stdout)
+	case "search":
+		parse(searchCmd, args[1:])
+
+		// Find the expenses whose description matches the search terms, best
+		// matches first, and keep as many as the limit allows.
+		found, err := expenseList.Search(strings.Join(searchCmd.Args(), " "))
+		if err != nil {
+			fail(err)
+		}
+		if *searchLimit < 0 {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid limit %d: limit must not be negative", *searchLimit)})
+		}
+		if *searchLimit > 0 && *searchLimit < len(found) {
+			found = found[:*searchLimit]
+		}
+
+		// Write the matching expenses to the STDOUT.
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, listResult{Expenses: found.Records()})
+			return
+		}
+		found.List(os.Stdout)
 	case "summary":
-		if err := summaryCmd.Parse(os.Args[2:]); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		parse(summaryCmd, args[1:])
+
+		// Narrow the expenses down to the supplied date range.
+		summaryFromDate, summaryToDate, err := parseDateRange(*summaryFrom, *summaryTo)
+		if err != nil {
+			fail(err)
+		}
+		rangeList, err := store.Query(expense.Query{From: summaryFromDate, To: summaryToDate})
+		if err != nil {
+			fail(err)
+		}
+
+		// Convert every expense into the currency the summary is reported in.
+		// Unless a currency was requested, a ledger kept in a single currency is
+		// reported in that currency.
+		currency := *reportCurrency
+		if currencies := rangeList.Currencies(); len(currencies) == 1 && !isFlagSet(summaryCmd, "report-currency") {
+			currency = currencies[0]
+		}
+		reportList, err := rangeList.Convert(rateList, currency)
+		if err != nil {
+			fail(err)
+		}
+
+		// If a grouping was specified, total the expenses of every group.
+		var totals expense.Totals
+		var heading string
+		switch *groupBy {
+		case "":
+		case "category":
+			totals, heading = reportList.TotalsByCategory(), "Category"
+		case "tag":
+			totals, heading = reportList.TotalsByTag(), "Tag"
+		case "account":
+			totals, heading = reportList.TotalsByAccount(), "Account"
+		default:
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid group: %q is not supported", *groupBy)})
+		}
+
+		// Otherwise total the expenses of every month of the year if a monthly
+		// breakdown was requested, or of the month, the year or all expenses.
+		switch {
+		case heading != "":
+		case *monthly:
+			totals, heading = reportList.TotalsByMonth(*year), "Month"
+		case *month != 0:
+			if totals, err = reportList.TotalsForMonth(*year, *month); err != nil {
+				fail(err)
+			}
+		case isFlagSet(summaryCmd, "year"):
+			totals = reportList.TotalsForYear(*year)
+		default:
+			totals = reportList.Totals()
+		}
+
+		// Report the totals in the currency converted into, even if there were no
+		// expenses to convert. Convert has already validated the currency code.
+		totals.Currency = strings.ToUpper(currency)
+
+		// Compare the spending of the month against the budgets, if any. Budgets
+		// are kept in the default currency.
+		var budgetReportList expense.ExpenseList
+		if *month != 0 && heading == "" && len(budgetList) > 0 {
+			if budgetReportList, err = expenseList.Convert(rateList, expense.DefaultCurrency); err != nil {
+				fail(err)
+			}
+			if totals.Budgets, err = budgetList.Statuses(budgetReportList, *year, *month); err != nil {
+				fail(err)
+			}
+		}
+
+		// Write the totals to the STDOUT, as a JSON object if JSON output was requested.
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, totals)
+			return
+		}
+		if heading != "" {
+			totals.WriteTable(os.Stdout, heading)
+			return
+		}
+		totals.WriteSummary(os.Stdout, reportList.HasIncome())
+		if len(totals.Budgets) > 0 {
+			if err := budgetList.Report(os.Stdout, budgetReportList, *year, *month); err != nil {
+				fail(err)
+			}
+		}
+	case "delete":
+		parse(deleteCmd, args[1:])
+
+		// Move an expense from the list to the trash.
+		if err := expenseList.Delete(*id); err != nil {
+			fail(err)
+		}
+		// Write success message to STDOUT.
+		if *output == textOutput {
+			fmt.Println("Expense deleted successfully")
+		}
+
+		// Store the deleted expense.
+		item, err := expenseList.Trashed(*id)
+		if err != nil {
+			fail(err)
+		}
+		if err := store.Update(item); err != nil {
+			fail(err)
+		}
+
+		// Write the deleted expense to the STDOUT, if JSON output was requested.
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record()})
+		}
+
+	case "update":
+		parse(updateCmd, args[1:])
+
+		// Parse the new amount if one was supplied. Update leaves the amount
+		// unchanged when given a negative one, so a negative amount supplied
+		// by the user is rejected rather than ignored.
+		updateAmount := expense.Money(-1)
+		if *newAmount != "" {
+			parsed, err := expense.ParseMoney(*newAmount)
+			if err != nil {
+				fail(err)
+			}
+			if parsed < 0 {
+				fail(&expense.InputError{Message: "negative amount"})
+			}
+			updateAmount = parsed
+		}
+
+		// Parse the new date if one was supplied, a zero date leaves the date unchanged.
+		var updateDate time.Time
+		if *newDate != "" {
+			parsed, err := parseDate(*newDate)
+			if err != nil {
+				fail(err)
+			}
+			updateDate = parsed
+		}
+
+		// Update the expense based on the supplied ID, description, amount, category and date.
+		if err := expenseList.Update(*newID, *newDescription, updateAmount, *newCategory, updateDate); err != nil {
+			fail(err)
+		}
+		if len(updateTags) > 0 {
+			if err := expenseList.SetTags(*newID, updateTags); err != nil {
+				fail(err)
+			}
+		}
+
+		// Change the account the expense was paid from, which must be defined.
+		if isFlagSet(updateCmd, "account") {
+			if *updateAccount != "" {
+				if _, err := accountList.Get(*updateAccount); err != nil {
+					fail(err)
+				}
+			}
+			if err := expenseList.SetAccount(*newID, *updateAccount); err != nil {
+				fail(err)
+			}
+		}
+
+		// Write success message to the STDOUT.
+		if *output == textOutput {
+			fmt.Printf("Expense updated successfully (ID: %d)\n", *newID)
+		}
+
+		// Store the updated expense.
+		item, err := expenseList.Get(*newID)
+		if err != nil {
+			fail(err)
+		}
+		if err := store.Update(item); err != nil {
+			fail(err)
+		}
+
+		// Store the refunds of the expense, which follow its new date and category.
+		if *newDate != "" || *newCategory != "" {
+			for _, refund := range expenseList.Refunds(*newID) {
+				if err := store.Update(refund); err != nil {
+					fail(err)
+				}
+			}
+		}
+
+		// Warn about any budget the updated expense has exceeded.
+		warnings := budgetWarnings(budgetList, expenseList, rateList, item.Date, partCategories(item.Record())...)
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record(), Warnings: warnings})
+			return
+		}
+		displayWarnings(warnings)
+	case "split":
+		parse(splitCmd, args[1:])
+
+		// Parse the parts, no parts removes the split.
+		var parts []expense.Part
+		for _, value := range splitParts {
+			part, err := expense.ParsePart(value)
+			if err != nil {
+				fail(err)
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) == 0 && !*splitClear {
+			fail(&expense.InputError{Message: "no parts: give --part at least twice, or --clear to remove the split"})
+		}
+
+		// Split the expense with the supplied ID into the parts.
+		if err := expenseList.Split(*splitID, parts); err != nil {
+			fail(err)
+		}
+
+		// Store the split expense and its refunds, which are spread over its parts.
+		item, err := expenseList.Get(*splitID)
+		if err != nil {
+			fail(err)
+		}
+		if err := store.Update(item); err != nil {
+			fail(err)
+		}
+		for _, refund := range expenseList.Refunds(*splitID) {
+			if err := store.Update(refund); err != nil {
+				fail(err)
+			}
+		}
+
+		// Warn about any budget the parts of the expense have exceeded.
+		warnings := budgetWarnings(budgetList, expenseList, rateList, item.Date, partCategories(item.Record())...)
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record(), Warnings: warnings})
+			return
+		}
+		if len(parts) == 0 {
+			fmt.Printf("Split removed successfully (ID: %d)\n", item.ID)
+		} else {
+			fmt.Printf("Expense split successfully (ID: %d, parts: %d)\n", item.ID, len(parts))
+		}
+		displayWarnings(warnings)
+	case "refund":
+		parse(refundCmd, args[1:])
+
+		// Record the refund against the expense with the supplied ID.
+		if err := expenseList.Refund(*refundID, refundAmount, *refundDescription); err != nil {
+			fail(err)
+		}
+		if err := store.Renumber(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
+		}
+
+		// Store the new refund.
+		item := expenseList[len(expenseList)-1]
+		if err := store.Append(expenseList[len(expenseList)-1:]); err != nil {
+			fail(err)
+		}
+
+		// Write successful message to the STDOUT.
+		if *output == jsonOutput {
+			writeJSON(os.Stdout, expenseResult{Expense: item.Record()})
+			return
+		}
+		fmt.Printf("Refund added successfully (ID: %d, refund of expense %d)\n", item.ID, *refundID)
+	case "budget":
+		if len(args) < 2 {
+			displayUsage(budgetSetCmd, budgetListCmd, budgetRemoveCmd)
 			os.Exit(1)
 		}
 
-		// If month was not specified, simply generate the summary for all expenses
-		// and write to the STDOUT.
-		if *month == 0 {
-			expenseList.Summary(os.Stdout)
+		switch args[1] {
+		case "set":
+			parse(budgetSetCmd, args[2:])
+
+			// Set the budget for the supplied category.
+			if err := budgetList.Set(*budgetCategory, budgetAmount); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Budget set successfully")
+		case "list":
+			parse(budgetListCmd, args[2:])
+
+			// Write the list of budgets to the STDOUT.
+			budgetList.List(os.Stdout)
 			return
+		case "remove":
+			parse(budgetRemoveCmd, args[2:])
+
+			// Remove the budget for the supplied category.
+			if err := budgetList.Remove(*removeCategory); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Budget removed successfully")
+		default:
+			displayUsage(budgetSetCmd, budgetListCmd, budgetRemoveCmd)
+			os.Exit(1)
 		}
 
-		// If month was specified then generate summary for the provided month and
-		// write to the STDOUT.
-		if err := expenseList.SummaryForMonth(os.Stdout, *month); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		// Save the new budget list.
+		if err := budgetList.Save(ledger.Budgets); err != nil {
+			fail(err)
+		}
+	case "rates":
+		if len(args) < 2 {
+			displayUsage(rateSetCmd, rateListCmd, rateRemoveCmd)
 			os.Exit(1)
 		}
-	case "delete":
-		if err := deleteCmd.Parse(os.Args[2:]); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+
+		switch args[1] {
+		case "set":
+			parse(rateSetCmd, args[2:])
+
+			// Set the exchange rate between the supplied currencies.
+			if err := rateList.Set(*rateFrom, *rateTo, *rateValue); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Rate set successfully")
+		case "list":
+			parse(rateListCmd, args[2:])
+
+			// Write the exchange rates to the STDOUT.
+			rateList.List(os.Stdout)
+			return
+		case "remove":
+			parse(rateRemoveCmd, args[2:])
+
+			// Remove the exchange rate between the supplied currencies.
+			if err := rateList.Remove(*removeFrom, *removeTo); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Rate removed successfully")
+		default:
+			displayUsage(rateSetCmd, rateListCmd, rateRemoveCmd)
 			os.Exit(1)
 		}
 
-		// Delete an expense from the list.
-		if err := expenseList.Delete(*id); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		// Save the new exchange rates.
+		if err := rateList.Save(ledger.Rates); err != nil {
+			fail(err)
+		}
+	case "recurring":
+		if len(args) < 2 {
+			displayUsage(recurringAddCmd, recurringListCmd, recurringPauseCmd, recurringResumeCmd, recurringDeleteCmd, recurringMaterializeCmd)
 			os.Exit(1)
 		}
-		// Write success message to STDOUT.
-		fmt.Println("Expense deleted successfully")
 
-		// Save the new list.
-		if err := expenseList.Save(filename); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		switch args[1] {
+		case "add":
+			parse(recurringAddCmd, args[2:])
+
+			// The first occurrence defaults to today, from the start of the day
+			// like a date given with --start.
+			now := time.Now()
+			startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+			if *start != "" {
+				parsed, err := parseDate(*start)
+				if err != nil {
+					fail(err)
+				}
+				startDate = parsed
+			}
+
+			// Add the new recurring expense rule.
+			schedule := expense.Schedule{Frequency: *frequency, Interval: *interval, Start: startDate}
+			if err := recurringList.Add(*recurringDescription, recurringAmount, *recurringCategory, *recurringCurrency, schedule); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Recurring expense added successfully (ID: %d)\n", recurringList[len(recurringList)-1].ID)
+		case "list":
+			parse(recurringListCmd, args[2:])
+
+			// Write the recurring expense rules to the STDOUT.
+			recurringList.List(os.Stdout)
+			return
+		case "pause":
+			parse(recurringPauseCmd, args[2:])
+
+			// Pause the recurring expense rule.
+			if err := recurringList.Pause(*pauseID); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Recurring expense paused successfully (ID: %d)\n", *pauseID)
+		case "resume":
+			parse(recurringResumeCmd, args[2:])
+
+			// Resume the recurring expense rule.
+			if err := recurringList.Resume(*resumeID, time.Now()); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Recurring expense resumed successfully (ID: %d)\n", *resumeID)
+		case "delete":
+			parse(recurringDeleteCmd, args[2:])
+
+			// Delete the recurring expense rule.
+			if err := recurringList.Delete(*recurringID); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Recurring expense deleted successfully")
+		case "materialize":
+			parse(recurringMaterializeCmd, args[2:])
+
+			// Add every occurrence that is due to the expense list.
+			before := len(expenseList)
+			added, err := recurringList.Materialize(&expenseList, time.Now())
+			if err != nil {
+				fail(err)
+			}
+			if err := store.Renumber(expenseList[before:]); err != nil {
+				fail(err)
+			}
+
+			// Store the new expenses before the rules, so that a failure in
+			// between can only leave occurrences that are recognized next time.
+			if err := store.Append(expenseList[before:]); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Recurring expenses materialized successfully (added: %d)\n", added)
+		default:
+			displayUsage(recurringAddCmd, recurringListCmd, recurringPauseCmd, recurringResumeCmd, recurringDeleteCmd, recurringMaterializeCmd)
 			os.Exit(1)
 		}
 
-	case "update":
-		if err := updateCmd.Parse(os.Args[2:]); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		// Save the new recurring expense rules.
+		if err := recurringList.Save(ledger.Recurring); err != nil {
+			fail(err)
+		}
+	case "export":
+		parse(exportCmd, args[1:])
+
+		if *exportFormat != "csv" {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid format: %q is not supported", *exportFormat)})
+		}
+
+		// Write the expenses to the file given as argument, or to the STDOUT.
+		out := os.Stdout
+		if exportCmd.NArg() > 0 {
+			file, err := os.Create(exportCmd.Arg(0))
+			if err != nil {
+				fail(err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if err := expenseList.ExportCSV(out); err != nil {
+			fail(err)
+		}
+	case "import":
+		parse(importCmd, args[1:])
+
+		if *importFormat != "csv" {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid format: %q is not supported", *importFormat)})
+		}
+
+		comma := []rune(*delimiter)
+		if len(comma) != 1 {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid delimiter %q: expected a single character", *delimiter)})
+		}
+		decimal := []rune(*decimalSeparator)
+		if len(decimal) != 1 {
+			fail(&expense.InputError{Message: fmt.Sprintf("invalid decimal separator %q: expected . or ,", *decimalSeparator)})
+		}
+
+		// Read the expenses from the file given as argument, or from the STDIN.
+		in := os.Stdin
+		if importCmd.NArg() > 0 {
+			file, err := os.Open(importCmd.Arg(0))
+			if err != nil {
+				fail(err)
+			}
+			defer file.Close()
+			in = file
+		}
+
+		mapping := expense.CSVMapping{
+			Date:            *dateColumn,
+			Description:     *descriptionColumn,
+			Amount:          *amountColumn,
+			Category:        *categoryColumn,
+			Currency:        *currencyColumn,
+			Kind:            *kindColumn,
+			Tags:            *tagsColumn,
+			Account:         *accountColumn,
+			Accounts:        accountList,
+			DateLayout:      *dateFormat,
+			DefaultCurrency: *importCurrency,
+			Negate:          *negate,
+			Comma:           comma[0],
+			Decimal:         decimal[0],
+		}
+
+		before := len(expenseList)
+		rejected, err := expenseList.ImportCSV(in, mapping)
+		if err != nil {
+			fail(err)
+		}
+		if err := store.Renumber(expenseList[before:]); err != nil {
+			fail(err)
+		}
+
+		// Store the imported expenses.
+		if err := store.Append(expenseList[before:]); err != nil {
+			fail(err)
+		}
+
+		// Write success message to the STDOUT and the rejected rows to the STDERR.
+		fmt.Printf("Expenses imported successfully (imported: %d, rejected: %d)\n", len(expenseList)-before, len(rejected))
+		for _, row := range rejected {
+			fmt.Fprintln(os.Stderr, "Rejected", row)
+		}
+	case "trash":
+		if len(args) < 2 {
+			displayUsage(trashListCmd, trashRestoreCmd, trashEmptyCmd)
 			os.Exit(1)
 		}
 
-		// Update the expense based on the supplied ID, description and amount.
-		if err := expenseList.Update(*newID, *newDescription, *newAmount); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		switch args[1] {
+		case "list":
+			parse(trashListCmd, args[2:])
+
+			// Write the deleted expenses to the STDOUT.
+			expenseList.ListTrash(os.Stdout)
+		case "restore":
+			parse(trashRestoreCmd, args[2:])
+
+			// Move the expense out of the trash.
+			if err := expenseList.Restore(*restoreID); err != nil {
+				fail(err)
+			}
+
+			// Store the restored expense.
+			item, err := expenseList.Get(*restoreID)
+			if err != nil {
+				fail(err)
+			}
+			if err := store.Update(item); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Expense restored successfully (ID: %d)\n", *restoreID)
+		case "empty":
+			parse(trashEmptyCmd, args[2:])
+
+			// Only remove the expenses deleted before the cutoff, if one was supplied.
+			var cutoff time.Time
+			if *olderThan != "" {
+				age, err := parseAge(*olderThan)
+				if err != nil {
+					fail(err)
+				}
+				cutoff = time.Now().Add(-age)
+			}
+
+			// Remove the expenses from the store for good.
+			removed := expenseList.EmptyTrash(cutoff)
+			for _, id := range removed {
+				if err := store.Delete(id); err != nil {
+					fail(err)
+				}
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Trash emptied successfully (removed: %d)\n", len(removed))
+		default:
+			displayUsage(trashListCmd, trashRestoreCmd, trashEmptyCmd)
 			os.Exit(1)
 		}
+	case "history":
+		parse(historyCmd, args[1:])
+
+		history, err := store.History()
+		if err != nil {
+			fail(err)
+		}
+
+		// Write the changes to the STDOUT.
+		history.List(os.Stdout, *historyID)
+	case "undo":
+		parse(undoCmd, args[1:])
+
+		// Revert the most recent change.
+		undone, err := store.Undo()
+		if err != nil {
+			fail(err)
+		}
 
 		// Write success message to the STDOUT.
-		fmt.Printf("Expense updated successfully (ID: %d)\n", *newID)
+		fmt.Printf("Change undone successfully (%s)\n", undone)
+	case "redo":
+		parse(redoCmd, args[1:])
 
-		// Save the new expense list.
-		if err := expenseList.Save(filename); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		// Make the most recently undone change again.
+		redone, err := store.Redo()
+		if err != nil {
+			fail(err)
+		}
+
+		// Write success message to the STDOUT.
+		fmt.Printf("Change redone successfully (%s)\n", redone)
+	case "migrate":
+		parse(migrateCmd, args[1:])
+
+		if _, ok := backend.(*expense.FileStore); !ok {
+			fail(errors.New("cannot migrate: the ledger is already a database"))
+		}
+
+		destination := *migrateTo
+		if destination == "" {
+			destination = strings.TrimSuffix(ledger.Expenses, filepath.Ext(ledger.Expenses)) + ".db"
+		}
+
+		// A named ledger only finds its expenses under the names it looks for.
+		if *file == "" && os.Getenv(fileEnv) == "" {
+			name, err := expense.CurrentLedger()
+			if err != nil {
+				fail(err)
+			}
+			paths, err := expense.LedgerPaths(name)
+			if err != nil {
+				fail(err)
+			}
+			if absolute, err := filepath.Abs(destination); err != nil || !slices.Contains(paths[:len(paths)-1], absolute) {
+				fail(&expense.InputError{Message: fmt.Sprintf("cannot migrate the %s ledger to %s: expected one of %s",
+					name, destination, strings.Join(paths[:len(paths)-1], ", "))})
+			}
+		}
+
+		// Lock and open the store the expenses are moved into.
+		destinationLock, err := expense.AcquireLock(destination, lockTimeout)
+		if err != nil {
+			fail(err)
+		}
+		defer destinationLock.Release()
+
+		destinationStore, err := expense.OpenStore(destination)
+		if err != nil {
+			fail(err)
+		}
+		defer destinationStore.Close()
+		if _, ok := destinationStore.(*expense.FileStore); ok {
+			fail(fmt.Errorf("cannot migrate: %s is not a SQLite database or journal", destination))
+		}
+
+		// Copy the expenses into the new store.
+		migrated, err := expense.Migrate(backend, destinationStore)
+		if err != nil {
+			fail(err)
+		}
+
+		// Set the JSON file aside, so that it is not mistaken for the ledger.
+		if err := os.Rename(ledger.Expenses, ledger.Expenses+".migrated"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fail(err)
+		}
+
+		// Write success message to the STDOUT.
+		fmt.Printf("Expenses migrated successfully (migrated: %d, to: %s)\n", migrated, destination)
+	case "compact":
+		parse(compactCmd, args[1:])
+
+		journal, ok := backend.(*expense.JournalStore)
+		if !ok {
+			fail(errors.New("cannot compact: the ledger is not a journal"))
+		}
+
+		// Fold the events of the journal into a snapshot.
+		events, err := journal.Compact()
+		if err != nil {
+			fail(err)
+		}
+
+		// Write success message to the STDOUT.
+		fmt.Printf("Journal compacted successfully (events: %d)\n", events)
+	case "account":
+		if len(args) < 2 {
+			displayUsage(accountAddCmd, accountListCmd, accountRemoveCmd)
+			os.Exit(1)
+		}
+
+		switch args[1] {
+		case "add":
+			parse(accountAddCmd, args[2:])
+
+			// Add the account with the supplied name, type, currency and opening balance.
+			if err := accountList.Add(*accountName, *accountType, *accountCurrency, openingBalance); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Account added successfully (%s)\n", strings.ToLower(*accountName))
+		case "list":
+			parse(accountListCmd, args[2:])
+
+			// Write the list of accounts to the STDOUT.
+			accountList.List(os.Stdout)
+			return
+		case "remove":
+			parse(accountRemoveCmd, args[2:])
+
+			// Remove the account with the supplied name.
+			if err := accountList.Remove(*removeAccount); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Println("Account removed successfully")
+		default:
+			displayUsage(accountAddCmd, accountListCmd, accountRemoveCmd)
 			os.Exit(1)
 		}
+
+		// Save the new account list.
+		if err := accountList.Save(ledger.Accounts); err != nil {
+			fail(err)
+		}
+	case "balance":
+		parse(balanceCmd, args[1:])
+
+		// Write the spending and the balance of every account to the STDOUT.
+		if *output == jsonOutput {
+			balances, err := accountList.Balances(expenseList, rateList)
+			if err != nil {
+				fail(err)
+			}
+			writeJSON(os.Stdout, balanceResult{Accounts: balances})
+			return
+		}
+		if err := accountList.Balance(os.Stdout, expenseList, rateList); err != nil {
+			fail(err)
+		}
+	case "tag":
+		if len(args) < 2 {
+			displayUsage(tagRenameCmd)
+			os.Exit(1)
+		}
+
+		switch args[1] {
+		case "rename":
+			parse(tagRenameCmd, args[2:])
+			if tagRenameCmd.NArg() != 2 {
+				fail(&expense.InputError{Message: "tag rename expects the old and the new tag"})
+			}
+
+			// Rename the tag on every expense that has it and store them.
+			renamed, err := expenseList.RenameTag(tagRenameCmd.Arg(0), tagRenameCmd.Arg(1))
+			if err != nil {
+				fail(err)
+			}
+			for _, item := range renamed {
+				if err := store.Update(item); err != nil {
+					fail(err)
+				}
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Tag renamed successfully (expenses: %d)\n", len(renamed))
+		default:
+			displayUsage(tagRenameCmd)
+			os.Exit(1)
+		}
+	case "ledger":
+		if len(args) < 2 {
+			displayUsage(ledgerCreateCmd, ledgerSwitchCmd, ledgerListCmd)
+			os.Exit(1)
+		}
+
+		switch args[1] {
+		case "create":
+			parse(ledgerCreateCmd, args[2:])
+
+			if err := expense.CreateLedger(ledgerCreateCmd.Arg(0)); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Ledger created successfully (%s)\n", ledgerCreateCmd.Arg(0))
+		case "switch":
+			parse(ledgerSwitchCmd, args[2:])
+
+			if err := expense.SwitchLedger(ledgerSwitchCmd.Arg(0)); err != nil {
+				fail(err)
+			}
+
+			// Write success message to the STDOUT.
+			fmt.Printf("Switched to ledger %s\n", ledgerSwitchCmd.Arg(0))
+			if *file != "" || os.Getenv(fileEnv) != "" {
+				displayWarnings([]string{"the ledger is not used while --file or " + fileEnv + " is set"})
+			}
+		case "list":
+			parse(ledgerListCmd, args[2:])
+
+			names, err := expense.Ledgers()
+			if err != nil {
+				fail(err)
+			}
+			current, err := expense.CurrentLedger()
+			if err != nil {
+				fail(err)
+			}
+
+			// Mark the selected ledger with an asterisk.
+			for _, name := range names {
+				marker := " "
+				if name == current {
+					marker = "*"
+				}
+				fmt.Println(marker, name)
+			}
+		default:
+			displayUsage(ledgerCreateCmd, ledgerSwitchCmd, ledgerListCmd)
+			os.Exit(1)
+		}
+	default:
+		fail(&expense.InputError{Message: fmt.Sprintf("unknown command %q", args[0])})
 	}
 }
 
@@ -132,3 +1331,185 @@ func displayUsage(flagSets ...*flag.FlagSet) {
 		flagSet.Usage()
 	}
 }
+
+// ledgerPath returns the path of the file the expenses are kept in: the file
+// given with the --file flag, else the file named by the EXPENSE_TRACKER_FILE
+// environment variable, else the file of the selected named ledger.
+func ledgerPath(file string) (string, error) {
+	if file != "" {
+		return file, nil
+	}
+	if file := os.Getenv(fileEnv); file != "" {
+		return file, nil
+	}
+
+	name, err := expense.CurrentLedger()
+	if err != nil {
+		return "", err
+	}
+	return expense.LedgerPath(name)
+}
+
+// repeatedFlag is a flag that can be given several times, such as --tag,
+// and collects every value it is given.
+type repeatedFlag []string
+
+// String returns the values of the flag separated by commas.
+func (f *repeatedFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds the value to the values of the flag.
+func (f *repeatedFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// isFlagSet reports whether the flag with the given name was set on the command line.
+func isFlagSet(flagSet *flag.FlagSet, name string) bool {
+	set := false
+	flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// displayWarnings writes each of the warnings to the STDERR.
+func displayWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", warning)
+	}
+}
+
+// budgetWarnings converts the expenses into the default currency the budgets
+// are kept in and returns the warnings for every budget exceeded in the month
+// of the given date.
+func budgetWarnings(budgets expense.BudgetList, expenses expense.ExpenseList, rates expense.RateList, date time.Time, categories ...string) []string {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	converted, err := expenses.Convert(rates, expense.DefaultCurrency)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot check budgets: %v", err)}
+	}
+	return budgets.Warnings(converted, date, categories...)
+}
+
+// partCategories returns the categories of the parts of a split expense, or its
+// category if it is not split.
+func partCategories(record expense.Record) []string {
+	if len(record.Parts) == 0 {
+		return []string{record.Category}
+	}
+
+	categories := make([]string, 0, len(record.Parts))
+	for _, part := range record.Parts {
+		categories = append(categories, part.Category)
+	}
+	return categories
+}
+
+// listResult is the JSON output of the list command.
+type listResult struct {
+	Expenses []expense.Record `json:"expenses"`
+}
+
+// expenseResult is the JSON output of the add, update and delete commands: the
+// expense as stored and the warnings about the budgets it has exceeded.
+type expenseResult struct {
+	Expense  expense.Record `json:"expense"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// balanceResult is the JSON output of the balance command.
+type balanceResult struct {
+	Accounts []expense.AccountBalance `json:"accounts"`
+}
+
+// errorResult is the JSON output of a command that failed.
+type errorResult struct {
+	Error errorDetail `json:"error"`
+}
+
+// errorDetail describes the error of a command that failed. The code tells
+// scripts the kind of failure, and the message is meant for people.
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorCode returns the code identifying the kind of the error in the JSON
+// output: not_found, locked, invalid_input, or error for any other failure.
+func errorCode(err error) string {
+	var notFound *expense.NotFoundError
+	var locked *expense.LockedError
+	var input *expense.InputError
+	switch {
+	case errors.As(err, &notFound):
+		return "not_found"
+	case errors.As(err, &locked):
+		return "locked"
+	case errors.As(err, &input):
+		return "invalid_input"
+	default:
+		return "error"
+	}
+}
+
+// writeJSON writes the value to the provided io.Writer as indented JSON.
+func writeJSON(w io.Writer, v any) {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// parseDate parses a YYYY-MM-DD date in the local time zone.
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, &expense.InputError{Message: fmt.Sprintf("invalid date %q: expected YYYY-MM-DD", value)}
+	}
+	return date, nil
+}
+
+// parseAge parses a duration such as "30d" or "12h". On top of the units
+// understood by time.ParseDuration, it accepts whole days with the "d" unit.
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return age, nil
+	}
+	return 0, &expense.InputError{Message: fmt.Sprintf("invalid age %q: expected a duration such as 30d or 12h", value)}
+}
+
+// parseDateRange parses the optional YYYY-MM-DD from and to dates of a range
+// that includes both days. It returns the start of the from day and the start
+// of the day after the to day, or zero times for the dates that are empty.
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	var fromDate, toDate time.Time
+	var err error
+
+	if from != "" {
+		if fromDate, err = parseDate(from); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if to != "" {
+		if toDate, err = parseDate(to); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		toDate = toDate.AddDate(0, 0, 1)
+	}
+
+	if !fromDate.IsZero() && !toDate.IsZero() && !fromDate.Before(toDate) {
+		return time.Time{}, time.Time{}, &expense.InputError{Message: fmt.Sprintf("invalid date range: %s is after %s", from, to)}
+	}
+	return fromDate, toDate, nil
+}
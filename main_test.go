@@ -1,17 +1,24 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/hayohtee/expense-tracker/internal/expense"
 )
 
 var binName = "expense-tracker"
 
+// filename is the file the tests keep the expenses in.
+const filename = ".expense_list.json"
+
 func TestMain(m *testing.M) {
 	fmt.Println("Building tool...")
 
@@ -27,11 +34,27 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	// Keep the expenses in the working directory, and the named ledgers in a
+	// temporary directory rather than in the data directory of the user.
+	dataDir, err := os.MkdirTemp("", "expense-tracker")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_DATA_HOME", dataDir)
+	os.Setenv(fileEnv, filename)
+
 	fmt.Println("Running tests...")
 	result := m.Run()
 
 	os.Remove(binName)
-	os.Remove(filename)
+	os.RemoveAll(dataDir)
+	ledger := expense.NewLedger(filename)
+	for _, name := range []string{ledger.Expenses, ledger.Budgets, ledger.Rates, ledger.Recurring, ledger.History, ledger.Accounts} {
+		os.Remove(name)
+		os.Remove(name + ".bak")
+	}
+	os.Remove(filename + ".lock")
 
 	os.Exit(result)
 }
@@ -47,17 +70,18 @@ func TestExpenseTracker(t *testing.T) {
 	expenses := []struct {
 		description string
 		amount      float64
+		category    string
 	}{
-		{description: "new expense 1", amount: 20.00},
-		{description: "new expense 2", amount: 20.60},
-		{description: "new expense 3", amount: 200.50},
-		{description: "new expense 4", amount: 200.00},
+		{description: "new expense 1", amount: 20.00, category: "food"},
+		{description: "new expense 2", amount: 20.60, category: "food"},
+		{description: "new expense 3", amount: 200.50, category: "travel"},
+		{description: "new expense 4", amount: 200.00, category: "software"},
 		{description: "new expense 5", amount: 120.00},
 	}
 
 	t.Run("TestAddCMD", func(t *testing.T) {
 		for index, item := range expenses {
-			cmd := exec.Command(cmdPath, "add", "--description", item.description, "--amount", fmt.Sprint(item.amount))
+			cmd := exec.Command(cmdPath, "add", "--description", item.description, "--amount", fmt.Sprint(item.amount), "--category", item.category)
 			out, err := cmd.CombinedOutput()
 			if err != nil {
 				t.Fatal(err)
@@ -95,7 +119,23 @@ func TestExpenseTracker(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		expected = fmt.Sprintf("Total expenses for %s: $%.2f\n", time.Month(currentMonth).String(), totalExpenses)
+		expected = fmt.Sprintf("Total expenses for %s %d: $%.2f\n", time.Month(currentMonth).String(), time.Now().Year(), totalExpenses)
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--by", "category")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected = fmt.Sprintf("%-20s%s\n", "Category", "Total") +
+			fmt.Sprintf("%-20s$%.2f\n", "food", 40.60) +
+			fmt.Sprintf("%-20s$%.2f\n", "software", 200.00) +
+			fmt.Sprintf("%-20s$%.2f\n", "travel", 200.50) +
+			fmt.Sprintf("%-20s$%.2f\n", "uncategorized", 120.00) +
+			fmt.Sprintf("%-20s$%.2f\n", "Total", totalExpenses)
 		if string(out) != expected {
 			t.Errorf("expected %q, but got %q instead", expected, string(out))
 		}
@@ -147,6 +187,12 @@ func TestExpenseTracker(t *testing.T) {
 			}
 		}
 
+		// Assert a negative amount is rejected rather than ignored.
+		cmd := exec.Command(cmdPath, "update", "--id", "1", "--amount", "-7")
+		out, err := cmd.CombinedOutput()
+		if err == nil || string(out) != "negative amount\n" {
+			t.Errorf("expected %q and an error, but got %q (%v) instead", "negative amount\n", string(out), err)
+		}
 	})
 
 	t.Run("TestDeleteCMD", func(t *testing.T) {
@@ -178,6 +224,1105 @@ func TestExpenseTracker(t *testing.T) {
 			t.Errorf("expected %q, but got %q instead", expected, string(out))
 		}
 
+		// Deleting the same ID again must fail instead of removing another expense.
+		cmd = exec.Command(cmdPath, "delete", "-id", "1")
+		out, err = cmd.CombinedOutput()
+		if err == nil {
+			t.Fatal("expected an error for a deleted ID, but got nil instead")
+		}
+		expected = "expense not found: no expense with ID 1\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// The remaining expenses keep their IDs.
+		cmd = exec.Command(cmdPath, "update", "-id", "3", "-category", "travel")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Expense updated successfully (ID: 3)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+	})
+
+	t.Run("TestBudgetCMD", func(t *testing.T) {
+		cmd := exec.Command(cmdPath, "budget", "set", "--amount", "200")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Budget set successfully\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "budget", "list")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = fmt.Sprintf("%-20s%s\n%-20s$%.2f\n", "Category", "Budget", "overall", 200.0)
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// The remaining expenses total $221.10, so adding another one must warn.
+		now := time.Now()
+		cmd = exec.Command(cmdPath, "add", "--description", "new expense 6", "--amount", "10")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Expense added successfully (ID: 6)\n" +
+			fmt.Sprintf("Warning: overall budget for %s %d exceeded: spent $231.10 of $200.00\n", now.Month(), now.Year())
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--month", fmt.Sprint(int(now.Month())))
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = fmt.Sprintf("Total expenses for %s %d: $%.2f\n", now.Month(), now.Year(), 231.10) +
+			fmt.Sprintf("%-20s%-14s%-14s%s\n", "Budget", "Spent", "Limit", "Remaining") +
+			fmt.Sprintf("%-20s%-14s%-14s%s\n", "overall", "$231.10", "$200.00", "-$31.10")
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "budget", "remove")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Budget removed successfully\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestRatesCMD", func(t *testing.T) {
+		cmd := exec.Command(cmdPath, "rates", "set", "--from", "EUR", "--to", "USD", "--rate", "1.10")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Rate set successfully\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "add", "--description", "new expense 7", "--amount", "11", "--currency", "eur")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Expense added successfully (ID: 7)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// The remaining $231.10 plus €11.00 converted at 1.10.
+		cmd = exec.Command(cmdPath, "summary")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Total expenses: $243.20\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--report-currency", "EUR")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Total expenses: €221.09\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--report-currency", "GBP")
+		if out, err = cmd.CombinedOutput(); err == nil {
+			t.Errorf("expected an error for a missing rate, but got %q instead", string(out))
+		}
+
+		// A ledger kept in a single currency is reported in that currency, without rates.
+		ledger := filepath.Join(t.TempDir(), "euros.json")
+		cmd = exec.Command(cmdPath, "--file", ledger, "add", "--description", "bread", "--amount", "5", "--currency", "EUR")
+		if out, err = cmd.CombinedOutput(); err != nil {
+			t.Fatal(string(out))
+		}
+		cmd = exec.Command(cmdPath, "--file", ledger, "summary")
+		if out, err = cmd.CombinedOutput(); err != nil {
+			t.Fatal(string(out))
+		}
+		expected = "Total expenses: €5.00\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestRecurringCMD", func(t *testing.T) {
+		start := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
+		cmd := exec.Command(cmdPath, "recurring", "add", "--description", "gym", "--amount", "30",
+			"--frequency", "weekly", "--start", start)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Recurring expense added successfully (ID: 1)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// Occurrences 14 and 7 days ago and today are due, but only once.
+		for _, added := range []int{3, 0} {
+			cmd = exec.Command(cmdPath, "recurring", "materialize")
+			out, err = cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected = fmt.Sprintf("Recurring expenses materialized successfully (added: %d)\n", added)
+			if string(out) != expected {
+				t.Errorf("expected %q, but got %q instead", expected, string(out))
+			}
+		}
+
+		cmd = exec.Command(cmdPath, "recurring", "pause", "--id", "1")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Recurring expense paused successfully (ID: 1)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "recurring", "delete", "--id", "1")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Recurring expense deleted successfully\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestDateRangeCMD", func(t *testing.T) {
+		// Only the two gym occurrences before today fall in the range.
+		from := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
+		to := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		cmd := exec.Command(cmdPath, "summary", "--from", from, "--to", to)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Total expenses: $60.00\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		cmd = exec.Command(cmdPath, "list", "--from", tomorrow)
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = fmt.Sprintf("%-6s%-14s%-70s%-20s%s\n", "ID", "Date", "Description", "Category", "Amount")
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "list", "--from", "2025-13-01")
+		if out, err = cmd.CombinedOutput(); err == nil {
+			t.Errorf("expected an error for an invalid date, but got %q instead", string(out))
+		}
+	})
+
+	t.Run("TestYearCMD", func(t *testing.T) {
+		// Nothing was spent last year.
+		lastYear := time.Now().Year() - 1
+		cmd := exec.Command(cmdPath, "summary", "--year", fmt.Sprint(lastYear), "--month", "1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := fmt.Sprintf("Total expenses for January %d: $0.00\n", lastYear)
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--year", fmt.Sprint(lastYear), "--monthly")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = fmt.Sprintf("%-20s%s\n", "Month", "Total")
+		for month := time.January; month <= time.December; month++ {
+			expected += fmt.Sprintf("%-20s%s\n", month, "$0.00")
+		}
+		expected += fmt.Sprintf("%-20s%s\n", fmt.Sprintf("Total %d", lastYear), "$0.00")
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestCSVCMD", func(t *testing.T) {
+		statement := filepath.Join(t.TempDir(), "statement.csv")
+		content := "Posted,Details,Debit\n2024-01-15,Coffee,3.50\n2024-01-16,Lunch,twelve\n"
+		if err := os.WriteFile(statement, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := exec.Command(cmdPath, "import", "--format", "csv", "--date-column", "Posted",
+			"--description-column", "Details", "--amount-column", "Debit", statement)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Expenses imported successfully (imported: 1, rejected: 1)\n" +
+			"Rejected line 3: invalid amount \"twelve\"\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "export", "--format", "csv")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if len(lines) != 9 {
+			t.Fatalf("expected a header and %d expenses, but got %q instead", 8, string(out))
+		}
+		if !strings.HasPrefix(lines[0], "id,date,description,category,amount,currency,") {
+			t.Errorf("expected a header row, but got %q instead", lines[0])
+		}
+		if !strings.Contains(lines[8], ",coffee,,3.50,USD,") {
+			t.Errorf("expected the imported expense, but got %q instead", lines[8])
+		}
+	})
+
+	t.Run("TestDateCMD", func(t *testing.T) {
+		cmd := exec.Command(cmdPath, "add", "--description", "old receipt", "--amount", "42", "--date", "2023-03-15")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "Expense added successfully (ID: 12)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// Editing the receipt must not move it into the current month.
+		cmd = exec.Command(cmdPath, "update", "--id", "12", "--amount", "24")
+		if out, err = cmd.CombinedOutput(); err != nil {
+			t.Fatal(string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "summary", "--year", "2023", "--month", "3")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = "Total expenses for March 2023: $24.00\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestConcurrentAddCMD", func(t *testing.T) {
+		count := func() int {
+			cmd := exec.Command(cmdPath, "export", "--format", "csv")
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return len(strings.Split(strings.TrimSpace(string(out)), "\n")) - 1
+		}
+		before := count()
+
+		// Add expenses from several processes at once.
+		const adds = 10
+		errs := make(chan error, adds)
+		for i := range adds {
+			go func() {
+				cmd := exec.Command(cmdPath, "add", "--description", fmt.Sprintf("concurrent expense %d", i), "--amount", "1")
+				if out, err := cmd.CombinedOutput(); err != nil {
+					errs <- fmt.Errorf("%v: %s", err, out)
+					return
+				}
+				errs <- nil
+			}()
+		}
+		for range adds {
+			if err := <-errs; err != nil {
+				t.Error(err)
+			}
+		}
+
+		// Assert that no expense was lost.
+		if after := count(); after != before+adds {
+			t.Errorf("expected %d expenses, but got %d instead", before+adds, after)
+		}
 	})
 
+	t.Run("TestLedgerCMD", func(t *testing.T) {
+		// Assert the --file flag overrides the file of the environment.
+		other := filepath.Join(t.TempDir(), "other.json")
+		cmd := exec.Command(cmdPath, "--file", other, "add", "--description", "elsewhere", "--amount", "5")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(string(out))
+		}
+		expected := "Expense added successfully (ID: 1)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		// Run the commands that follow against the named ledgers.
+		var env []string
+		for _, variable := range os.Environ() {
+			if !strings.HasPrefix(variable, fileEnv+"=") {
+				env = append(env, variable)
+			}
+		}
+		workDir := t.TempDir()
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, args...)
+			cmd.Env = env
+			cmd.Dir = workDir
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		expected = "Ledger created successfully (team-offsite)\n"
+		if out := run("ledger", "create", "team-offsite"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		run("ledger", "switch", "team-offsite")
+
+		expected = "Expense added successfully (ID: 1)\n"
+		if out := run("add", "--description", "venue", "--amount", "300"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		expected = "  default\n* team-offsite\n"
+		if out := run("ledger", "list"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		// Assert each ledger keeps its own expenses.
+		run("ledger", "switch", "default")
+		expected = "Total expenses: $0.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+	})
+
+	t.Run("TestMigrateCMD", func(t *testing.T) {
+		dir := t.TempDir()
+		ledger := filepath.Join(dir, "ledger.json")
+		database := filepath.Join(dir, "ledger.db")
+
+		for _, description := range []string{"lunch", "taxi"} {
+			cmd := exec.Command(cmdPath, "--file", ledger, "add", "--description", description, "--amount", "10")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatal(string(out))
+			}
+		}
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "migrate")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(string(out))
+		}
+		expected := fmt.Sprintf("Expenses migrated successfully (migrated: 2, to: %s)\n", database)
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		if _, err := os.Stat(ledger); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be moved aside, but got %v instead", ledger, err)
+		}
+
+		// Assert the database carries on where the file left off.
+		cmd = exec.Command(cmdPath, "--file", database, "add", "--description", "dinner", "--amount", "10")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(string(out))
+		}
+		expected = "Expense added successfully (ID: 3)\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+
+		cmd = exec.Command(cmdPath, "--file", database, "summary")
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatal(string(out))
+		}
+		expected = "Total expenses: $30.00\n"
+		if string(out) != expected {
+			t.Errorf("expected %q, but got %q instead", expected, string(out))
+		}
+	})
+
+	t.Run("TestJournalCMD", func(t *testing.T) {
+		journal := filepath.Join(t.TempDir(), "ledger.jsonl")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", journal}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "lunch", "--amount", "10")
+		run("add", "--description", "taxi", "--amount", "15")
+		run("delete", "--id", "1")
+
+		expected := "Journal compacted successfully (events: 3)\n"
+		if out := run("compact"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		expected = "Total expenses: $15.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		// Assert compact is refused for a ledger that is not a journal.
+		cmd := exec.Command(cmdPath, "compact")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error compacting a JSON ledger")
+		}
+	})
+
+	t.Run("TestHistoryCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "lunch", "--amount", "10")
+		run("add", "--description", "taxi", "--amount", "15")
+		run("update", "--id", "2", "--amount", "18")
+
+		// Delete the wrong expense and undo it.
+		run("delete", "--id", "2")
+		expected := "Change undone successfully (delete of expense 2)\n"
+		if out := run("undo"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		expected = "Total expenses: $28.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		lines := strings.Split(strings.TrimSpace(run("history", "--id", "2")), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected a header and %d changes, but got %q instead", 3, lines)
+		}
+		if !strings.Contains(lines[2], "update") || !strings.HasSuffix(lines[2], "amount: $15.00 -> $18.00") {
+			t.Errorf("expected the update of the amount, but got %q instead", lines[2])
+		}
+		if !strings.Contains(lines[3], "delete (undone)") {
+			t.Errorf("expected the undone delete, but got %q instead", lines[3])
+		}
+
+		expected = "Change redone successfully (delete of expense 2)\n"
+		if out := run("redo"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		// Assert the changes a command made to several expenses are undone together.
+		run("add", "--description", "coffee", "--amount", "3", "--tag", "work")
+		run("add", "--description", "snack", "--amount", "4", "--tag", "work")
+		run("tag", "rename", "work", "office")
+		expected = "Change undone successfully (update of expense 4, update of expense 3)\n"
+		if out := run("undo"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		lines = strings.Split(strings.TrimSpace(run("list", "--tag", "work")), "\n")
+		if len(lines) != 3 {
+			t.Errorf("expected both expenses tagged work again, but got %q instead", lines)
+		}
+
+		expected = "Change redone successfully (update of expense 3, update of expense 4)\n"
+		if out := run("redo"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		lines = strings.Split(strings.TrimSpace(run("list", "--tag", "office")), "\n")
+		if len(lines) != 3 {
+			t.Errorf("expected both expenses tagged office again, but got %q instead", lines)
+		}
+	})
+
+	t.Run("TestTrashCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "lunch", "--amount", "10")
+		run("add", "--description", "hotel", "--amount", "150")
+		run("delete", "--id", "2")
+
+		expected := "Total expenses: $10.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		lines := strings.Split(strings.TrimSpace(run("trash", "list")), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], "2     ") {
+			t.Errorf("expected the deleted expense in the trash, but got %q instead", lines)
+		}
+
+		expected = "Expense restored successfully (ID: 2)\n"
+		if out := run("trash", "restore", "--id", "2"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		expected = "Total expenses: $160.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		run("delete", "--id", "2")
+		expected = "Trash emptied successfully (removed: 0)\n"
+		if out := run("trash", "empty", "--older-than", "30d"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		expected = "Trash emptied successfully (removed: 1)\n"
+		if out := run("trash", "empty"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "trash", "restore", "--id", "2")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error restoring an expense removed from the trash")
+		}
+
+		// Assert the ID of the expense removed for good is not used again.
+		expected = "Expense added successfully (ID: 3)\n"
+		if out := run("add", "--description", "taxi", "--amount", "20"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+	})
+
+	t.Run("TestOutputJSONCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) []byte {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger, "--output", "json"}, args...)...)
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return out
+		}
+
+		var added struct {
+			Expense expense.Record `json:"expense"`
+		}
+		if err := json.Unmarshal(run("add", "--description", "lunch", "--amount", "12.50", "--category", "food"), &added); err != nil {
+			t.Fatal(err)
+		}
+		if added.Expense.ID != 1 || added.Expense.Amount != 12_50 || added.Expense.Category != "food" {
+			t.Errorf("expected the added expense, but got %+v instead", added.Expense)
+		}
+		run("add", "--description", "taxi", "--amount", "5")
+
+		var list struct {
+			Expenses []expense.Record `json:"expenses"`
+		}
+		if err := json.Unmarshal(run("list"), &list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Expenses) != 2 || list.Expenses[1].Description != "taxi" {
+			t.Errorf("expected the two expenses, but got %+v instead", list.Expenses)
+		}
+
+		var totals expense.Totals
+		if err := json.Unmarshal(run("summary", "--by", "category"), &totals); err != nil {
+			t.Fatal(err)
+		}
+		if totals.Total != 17_50 || len(totals.Groups) != 2 {
+			t.Errorf("expected the totals by category, but got %+v instead", totals)
+		}
+
+		// Assert errors are written to STDERR as an object with an error code.
+		cmd := exec.Command(cmdPath, "--file", ledger, "--output", "json", "delete", "--id", "9")
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err == nil {
+			t.Fatal("expected an error deleting a missing expense")
+		}
+
+		var failure struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(stderr.String()), &failure); err != nil {
+			t.Fatal(err)
+		}
+		if failure.Error.Code != "not_found" {
+			t.Errorf("expected %q, but got %q instead", "not_found", failure.Error.Code)
+		}
+
+		// Assert invalid flags and missing accounts are reported the same way,
+		// without the usage of the command.
+		for _, test := range []struct {
+			args []string
+			code string
+		}{
+			{args: []string{"add", "--description", "lunch", "--amount", "abc"}, code: "invalid_input"},
+			{args: []string{"add", "--description", "lunch", "--amount", "5", "--colour", "red"}, code: "invalid_input"},
+			{args: []string{"add", "--description", "lunch", "--amount", "5", "--account", "nope"}, code: "not_found"},
+			{args: []string{"trash", "list"}, code: "invalid_input"},
+			{args: []string{"undo"}, code: "invalid_input"},
+			{args: []string{"bogus"}, code: "invalid_input"},
+		} {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger, "--output", "json"}, test.args...)...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err == nil {
+				t.Fatalf("expected an error running %q", test.args)
+			}
+
+			failure.Error.Code = ""
+			if err := json.Unmarshal([]byte(stderr.String()), &failure); err != nil {
+				t.Fatalf("expected a JSON error running %q, but got %q instead", test.args, stderr.String())
+			}
+			if failure.Error.Code != test.code {
+				t.Errorf("expected %q running %q, but got %q instead", test.code, test.args, failure.Error.Code)
+			}
+		}
+
+		// Assert an unknown command leaves the directory of the ledger alone.
+		ledger = filepath.Join(t.TempDir(), "missing", "ledger.json")
+		cmd = exec.Command(cmdPath, "--file", ledger, "bogus")
+		if out, err := cmd.CombinedOutput(); err == nil || string(out) != "unknown command \"bogus\"\n" {
+			t.Errorf("expected an unknown command error, but got %q instead", string(out))
+		}
+		if _, err := os.Stat(filepath.Dir(ledger)); !os.IsNotExist(err) {
+			t.Errorf("expected no directory for the ledger, but got %v instead", err)
+		}
+	})
+
+	t.Run("TestListWhereCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "uber to airport", "--amount", "62", "--category", "food")
+		run("add", "--description", "lunch", "--amount", "12.50", "--category", "food")
+		run("add", "--description", "uber eats", "--amount", "55", "--category", "travel")
+
+		lines := strings.Split(strings.TrimSpace(run("list", "--where", `amount > 50 and category = "food" and description ~ "uber"`)), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], "1     ") {
+			t.Errorf("expected the first expense, but got %q instead", lines)
+		}
+
+		lines = strings.Split(strings.TrimSpace(run("list", "--sort", "amount", "--reverse", "--limit", "2")), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[1], "1     ") || !strings.HasPrefix(lines[2], "3     ") {
+			t.Errorf("expected the two largest expenses, but got %q instead", lines)
+		}
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "list", "--where", "amount ~ 5")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error for an invalid filter")
+		}
+	})
+
+	t.Run("TestSearchCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "starbucks coffee", "--amount", "5")
+		run("add", "--description", "coffee beans", "--amount", "12")
+		run("add", "--description", "uber to airport", "--amount", "30")
+
+		lines := strings.Split(strings.TrimSpace(run("search", "starbuks")), "\n")
+		if len(lines) != 2 || !strings.Contains(lines[1], "starbucks coffee") {
+			t.Errorf("expected the misspelled search to find starbucks coffee, but got %q instead", lines)
+		}
+
+		lines = strings.Split(strings.TrimSpace(run("search", "--limit", "1", "cof")), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], "1     ") {
+			t.Errorf("expected the best prefix match only, but got %q instead", lines)
+		}
+	})
+
+	t.Run("TestTagCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "flight", "--amount", "300", "--tag", "client-acme", "--tag", "billable")
+		run("add", "--description", "lunch", "--amount", "20", "--tag", "billable")
+		run("add", "--description", "groceries", "--amount", "50")
+
+		lines := strings.Split(strings.TrimSpace(run("list", "--tag", "client-acme")), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], "1     ") {
+			t.Errorf("expected the client expense, but got %q instead", lines)
+		}
+
+		expected := "Tag renamed successfully (expenses: 2)\n"
+		if out := run("tag", "rename", "billable", "invoiced"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		var expectedBuf strings.Builder
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Tag", "Total"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "client-acme", "$300.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "invoiced", "$320.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "untagged", "$50.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$370.00"))
+		if out := run("summary", "--by", "tag"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+
+		// Assert update replaces the tags, which the export carries along.
+		run("update", "--id", "3", "--tag", "household", "--tag", "invoiced")
+		lines = strings.Split(strings.TrimSpace(run("list", "--tag", "household")), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], "3     ") {
+			t.Errorf("expected the groceries, but got %q instead", lines)
+		}
+		if out := run("export"); !strings.Contains(out, `,"household,invoiced"`) {
+			t.Errorf("expected the tags in the export, but got %q instead", out)
+		}
+	})
+
+	t.Run("TestAccountCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		expected := "Account added successfully (visa)\n"
+		if out := run("account", "add", "--name", "visa", "--opening-balance", "1000"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		run("account", "add", "--name", "wallet", "--type", "cash", "--opening-balance", "50")
+
+		run("add", "--description", "hotel", "--amount", "120", "--account", "visa")
+		run("add", "--description", "coffee", "--amount", "4", "--account", "wallet")
+		run("add", "--description", "snack", "--amount", "3")
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "add", "--description", "taxi", "--amount", "9", "--account", "amex")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error paying from an undefined account")
+		}
+
+		var expectedBuf strings.Builder
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "Account", "Type", "Opening", "Income", "Spent", "Balance"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "visa", "card", "$1000.00", "$0.00", "$120.00", "$880.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%-10s%-16s%-16s%-16s%s\n", "wallet", "cash", "$50.00", "$0.00", "$4.00", "$46.00"))
+		if out := run("balance"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+
+		expectedBuf.Reset()
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Account", "Total"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "unassigned", "$3.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "visa", "$120.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "wallet", "$4.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$127.00"))
+		if out := run("summary", "--by", "account"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+
+		// Assert update moves an expense to a defined account only.
+		cmd = exec.Command(cmdPath, "--file", ledger, "update", "--id", "3", "--account", "amex")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error moving an expense to an undefined account")
+		}
+		run("update", "--id", "3", "--account", "wallet")
+		expectedBuf.Reset()
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Account", "Total"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "visa", "$120.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "wallet", "$7.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$127.00"))
+		if out := run("summary", "--by", "account"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+		if out := run("export"); !strings.Contains(out, ",snack,") || !strings.Contains(strings.Split(out, "\n")[3], ",wallet,") {
+			t.Errorf("expected the account in the export, but got %q instead", out)
+		}
+	})
+
+	t.Run("TestIncomeCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "rent", "--amount", "800", "--date", "2026-10-01")
+		expected := "Income added successfully (ID: 2)\n"
+		if out := run("income", "--description", "salary", "--amount", "3000", "--category", "salary", "--date", "2026-10-02"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		run("income", "--description", "bonus", "--amount", "500", "--date", "2026-11-02")
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "income", "--description", "refund", "--amount", "-5")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error adding income with a negative amount")
+		}
+
+		expected = "Total expenses: $800.00\nTotal income: $3500.00\nNet: $2700.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		expected = "Total expenses for October 2026: $800.00\nTotal income for October 2026: $3000.00\nNet for October 2026: $2200.00\n"
+		if out := run("summary", "--month", "10", "--year", "2026"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		if out := run("list", "--where", "kind = income"); !strings.Contains(out, "+$3000.00") || strings.Contains(out, "rent") {
+			t.Errorf("expected only the income in %q", out)
+		}
+	})
+
+	t.Run("TestRefundCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "supermarket", "--amount", "120", "--category", "food", "--date", "2026-09-20")
+		run("add", "--description", "taxi", "--amount", "20", "--category", "travel", "--date", "2026-10-03")
+
+		expected := "Refund added successfully (ID: 3, refund of expense 1)\n"
+		if out := run("refund", "--id", "1", "--amount", "30"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "refund", "--id", "1", "--amount", "100")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error refunding more than is left of the expense")
+		}
+
+		var expectedBuf strings.Builder
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Category", "Total"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "food", "$90.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "travel", "$20.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$110.00"))
+		if out := run("summary", "--by", "category"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+
+		expected = "Total expenses for September 2026: $90.00\n"
+		if out := run("summary", "--month", "9", "--year", "2026"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		// The refund follows the expense to its new category.
+		run("update", "--id", "1", "--category", "groceries")
+		if out := run("list", "--where", "kind = refund"); !strings.Contains(out, "groceries") || !strings.Contains(out, "+$30.00") {
+			t.Errorf("expected the refund in groceries in %q", out)
+		}
+	})
+
+	t.Run("TestSplitCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		run := func(args ...string) string {
+			cmd := exec.Command(cmdPath, append([]string{"--file", ledger}, args...)...)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("add", "--description", "supermarket", "--amount", "120", "--category", "shopping")
+		run("add", "--description", "taxi", "--amount", "20", "--category", "travel")
+
+		expected := "Expense split successfully (ID: 1, parts: 2)\n"
+		if out := run("split", "--id", "1", "--part", "groceries=90", "--part", "household=30"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		cmd := exec.Command(cmdPath, "--file", ledger, "split", "--id", "2", "--part", "travel=10", "--part", "food=5")
+		if err := cmd.Run(); err == nil {
+			t.Error("expected an error splitting into parts that do not add up to the expense")
+		}
+
+		var expectedBuf strings.Builder
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Category", "Total"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "groceries", "$90.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "household", "$30.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "travel", "$20.00"))
+		expectedBuf.WriteString(fmt.Sprintf("%-20s%s\n", "Total", "$140.00"))
+		if out := run("summary", "--by", "category"); out != expectedBuf.String() {
+			t.Errorf("expected %q, but got %q instead", expectedBuf.String(), out)
+		}
+
+		expected = "Split removed successfully (ID: 1)\n"
+		if out := run("split", "--id", "1", "--clear"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+		if out := run("summary", "--by", "category"); !strings.Contains(out, "shopping") {
+			t.Errorf("expected the expense back in shopping in %q", out)
+		}
+	})
+
+	t.Run("TestRecurringStartCMD", func(t *testing.T) {
+		ledger := filepath.Join(t.TempDir(), "ledger.json")
+		cmd := exec.Command(cmdPath, "--file", ledger, "recurring", "add", "--description", "gym", "--amount", "30", "--frequency", "weekly")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatal(string(out))
+		}
+
+		// A rule added without --start starts at the beginning of today.
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(ledger), "ledger_recurring.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rules []struct {
+			Schedule struct {
+				Start time.Time `json:"start"`
+			} `json:"schedule"`
+		}
+		if err := json.Unmarshal(content, &rules); err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now()
+		expected := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		if len(rules) != 1 || !rules[0].Schedule.Start.Equal(expected) {
+			t.Errorf("expected a rule starting on %v, but got %+v instead", expected, rules)
+		}
+	})
+
+	t.Run("TestLegacyFileCMD", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, ".expense_list.json"), []byte("[]"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Run without a file, so that the default ledger is used.
+		var env []string
+		for _, variable := range os.Environ() {
+			if !strings.HasPrefix(variable, fileEnv+"=") && !strings.HasPrefix(variable, "XDG_DATA_HOME=") {
+				env = append(env, variable)
+			}
+		}
+
+		cmd := exec.Command(cmdPath, "list")
+		cmd.Dir = dir
+		cmd.Env = append(env, "XDG_DATA_HOME="+filepath.Join(dir, "data"))
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatal(stderr.String())
+		}
+		if !strings.Contains(stderr.String(), "Warning: the ledger is empty, but .expense_list.json holds the expenses of an earlier version") {
+			t.Errorf("expected a warning about .expense_list.json, but got %q instead", stderr.String())
+		}
+	})
+
+	t.Run("TestMigrateLedgerCMD", func(t *testing.T) {
+		// Run the commands against a named ledger of a data directory of their own.
+		dataDir := t.TempDir()
+		env := []string{"XDG_DATA_HOME=" + dataDir}
+		for _, variable := range os.Environ() {
+			if !strings.HasPrefix(variable, fileEnv+"=") && !strings.HasPrefix(variable, "XDG_DATA_HOME=") {
+				env = append(env, variable)
+			}
+		}
+		workDir := t.TempDir()
+		command := func(args ...string) *exec.Cmd {
+			cmd := exec.Command(cmdPath, args...)
+			cmd.Env = env
+			cmd.Dir = workDir
+			return cmd
+		}
+		run := func(args ...string) string {
+			out, err := command(args...).CombinedOutput()
+			if err != nil {
+				t.Fatal(string(out))
+			}
+			return string(out)
+		}
+
+		run("ledger", "create", "trip")
+		run("ledger", "switch", "trip")
+		run("add", "--description", "hotel", "--amount", "80")
+
+		// Assert a destination the ledger would not find is refused.
+		if out, err := command("migrate", "--to", filepath.Join(workDir, "trip.jsonl")).CombinedOutput(); err == nil {
+			t.Errorf("expected an error migrating the ledger out of its directory, but got %q instead", out)
+		}
+
+		journal := filepath.Join(dataDir, "expense-tracker", "trip", "expense_list.jsonl")
+		expected := fmt.Sprintf("Expenses migrated successfully (migrated: 1, to: %s)\n", journal)
+		if out := run("migrate", "--to", journal); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+
+		// Assert the ledger carries on in its journal.
+		run("add", "--description", "museum", "--amount", "20")
+		expected = "Total expenses: $100.00\n"
+		if out := run("summary"); out != expected {
+			t.Errorf("expected %q, but got %q instead", expected, out)
+		}
+	})
 }